@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"honestman/schema"
+	"honestman/store"
+
+	"github.com/go-zoo/bone"
+)
+
+// CategoriesHandler category tree of every store, or one store by source
+// GET /api/categories
+func CategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	categories, err := AppContext.Categories.Categories(r.URL.Query().Get("source"))
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// [] rather than null when nothing crawled yet
+	if categories == nil {
+		categories = []schema.Category{}
+	}
+	ctx["category"] = categories
+	Render.JSON(w, http.StatusOK, ctx)
+}
+
+// CategoryItemsHandler items of category and its sub categories, same filters as search
+// GET /api/categories/:id/items
+func CategoryItemsHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category id"})
+		return
+	}
+
+	category, err := AppContext.Categories.GetCategory(id)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "category not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	q, err := searchQuery(r)
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	q.Category = id
+	ctx["category"] = category
+	ctx["page"] = q.Page
+	ctx["per_page"] = q.PerPage
+	if _, err = searchPage(w, r, AppContext.Items.Search, q, ctx); err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	Render.JSON(w, http.StatusOK, ctx)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"honestman/store"
+
+	"github.com/go-zoo/bone"
+)
+
+// CrawlsHandler recent crawl runs and the last success of every task
+// GET /api/crawls?task=RTmart&status=failed&limit=20
+func CrawlsHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+	var q = store.RunQuery{Limit: 20}
+
+	query := r.URL.Query()
+	q.Task = query.Get("task")
+	q.Status = query.Get("status")
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 200 {
+		q.Limit = limit
+	}
+
+	runs, err := AppContext.Runs.ListRuns(q)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	last, err := AppContext.Runs.LastSuccess()
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx["crawl"] = runs
+	ctx["last_success"] = last
+	Render.JSON(w, http.StatusOK, ctx)
+}
+
+// CrawlHandler one crawl run
+// GET /api/crawls/:id
+func CrawlHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid crawl id"})
+		return
+	}
+
+	run, err := AppContext.Runs.GetRun(id)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "crawl not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	Render.JSON(w, http.StatusOK, run)
+}
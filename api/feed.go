@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"honestman/app"
+	"honestman/schema"
+	"honestman/store"
+)
+
+// feedItems entries of a feed
+const feedItems = 50
+
+// feedEntry one item of a feed, kept by id in readers, a later time is an update
+type feedEntry struct {
+	ID      string
+	Title   string
+	Link    string
+	Image   string
+	Content string // html
+	Updated time.Time
+}
+
+// atomFeed of RFC 4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  string      `xml:"author>name"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Links   []atomLink `xml:"link"`
+	Content atomText   `xml:"content"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// rssFeed of RSS 2.0
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	ID          string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int    `xml:"length,attr"`
+}
+
+// baseURL of request, https behind a proxy as well
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// itemEntry of item, id stable while the item is kept
+func itemEntry(base string, item schema.Item, title string) feedEntry {
+	content := fmt.Sprintf(`<p>%s NT$%d`, html.EscapeString(item.Source), item.Price)
+	if item.OnSale && item.RegularPrice > item.Price {
+		content += fmt.Sprintf(` <del>NT$%d</del>`, item.RegularPrice)
+	}
+	content += "</p>"
+	if item.Imgsrc != "" {
+		content = fmt.Sprintf(`<p><img src="%s" alt="%s"></p>`, html.EscapeString(item.Imgsrc), html.EscapeString(item.Name)) + content
+	}
+	return feedEntry{
+		ID:      fmt.Sprintf("%s/api/items/%d", base, item.Id),
+		Title:   title,
+		Link:    item.Url,
+		Image:   item.Imgsrc,
+		Content: content,
+		Updated: item.Updated,
+	}
+}
+
+// writeFeed entries as atom, or rss when format is rss
+func writeFeed(w http.ResponseWriter, r *http.Request, title string, entries []feedEntry) {
+	self := baseURL(r) + r.URL.RequestURI()
+
+	// the newest entry, now for an empty feed
+	updated := time.Now()
+	if len(entries) > 0 {
+		updated = entries[0].Updated
+		for _, e := range entries {
+			if e.Updated.After(updated) {
+				updated = e.Updated
+			}
+		}
+	}
+
+	var doc interface{}
+	if r.URL.Query().Get("format") == "rss" {
+		w.Header().Set("Content-type", "application/rss+xml;charset=utf-8")
+		feed := rssFeed{Version: "2.0", Channel: rssChannel{
+			Title:         title,
+			Link:          self,
+			Description:   title,
+			LastBuildDate: updated.Format(time.RFC1123Z),
+		}}
+		for _, e := range entries {
+			item := rssItem{
+				Title:       e.Title,
+				Link:        e.Link,
+				GUID:        rssGUID{ID: e.ID},
+				PubDate:     e.Updated.Format(time.RFC1123Z),
+				Description: e.Content,
+			}
+			if e.Image != "" {
+				item.Enclosure = &rssEnclosure{URL: e.Image, Type: "image/jpeg"}
+			}
+			feed.Channel.Items = append(feed.Channel.Items, item)
+		}
+		doc = feed
+	} else {
+		w.Header().Set("Content-type", "application/atom+xml;charset=utf-8")
+		feed := atomFeed{
+			ID:      self,
+			Title:   title,
+			Updated: updated.Format(time.RFC3339),
+			Links:   []atomLink{{Href: self, Rel: "self", Type: "application/atom+xml"}},
+			Author:  app.PackageName,
+		}
+		for _, e := range entries {
+			entry := atomEntry{
+				ID:      e.ID,
+				Title:   e.Title,
+				Updated: e.Updated.Format(time.RFC3339),
+				Links:   []atomLink{{Href: e.Link, Rel: "alternate"}},
+				Content: atomText{Type: "html", Body: e.Content},
+			}
+			if e.Image != "" {
+				entry.Links = append(entry.Links, atomLink{Href: e.Image, Rel: "enclosure", Type: "image/jpeg"})
+			}
+			feed.Entries = append(feed.Entries, entry)
+		}
+		doc = feed
+	}
+
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		log.Println(err)
+	}
+}
+
+// SearchFeedHandler items of a search, the latest updated first, q is required
+// GET /api/feeds/search?q=蜂蜜&format=rss
+func SearchFeedHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := searchQuery(r)
+	if err == nil && !q.Filtered() {
+		err = fmt.Errorf("q is required")
+	}
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	q.Sort = "updated"
+	q.Page, q.PerPage, q.Cursor = 1, feedItems, nil
+
+	items, _, err := AppContext.Search.Search(q)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	base := baseURL(r)
+	var entries []feedEntry
+	for _, item := range items {
+		entries = append(entries, itemEntry(base, item, fmt.Sprintf("%s NT$%d", item.Name, item.Price)))
+	}
+	writeFeed(w, r, fmt.Sprintf("%s: %s", app.PackageName, r.URL.Query().Get("q")), entries)
+}
+
+// DropsFeedHandler items dropped the most in percent over window, as /api/movers
+// GET /api/feeds/drops?window=1d&source=RTmart&category=3&format=rss
+func DropsFeedHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	window := query.Get("window")
+	if window == "" {
+		window = defaultWindow
+	}
+	d, err := parseWindow(window)
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	mq := store.MoverQuery{Since: time.Now().Add(-d), Source: query.Get("source"), Limit: feedItems}
+	if s := query.Get("category"); s != "" {
+		if mq.Category, err = strconv.Atoi(s); err != nil {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category id"})
+			return
+		}
+	}
+
+	movers, err := AppContext.Items.Movers(mq)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	base := baseURL(r)
+	var entries []feedEntry
+	for _, m := range movers {
+		e := itemEntry(base, m.Item, fmt.Sprintf("%s NT$%d → NT$%d (%.1f%%)", m.Name, m.From, m.Price, m.Percent))
+		// a new drop of the same item is a new entry
+		e.ID = fmt.Sprintf("%s/drops/%d", e.ID, m.Price)
+		entries = append(entries, e)
+	}
+
+	title := fmt.Sprintf("%s: price drops in %s", app.PackageName, window)
+	if mq.Source != "" {
+		title += " of " + mq.Source
+	}
+	writeFeed(w, r, title, entries)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"honestman/schema"
+	"honestman/store"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-zoo/bone"
+)
+
+const dateLayout = "2006-01-02"
+
+// historyPeriods raw keep every observation
+var historyPeriods = map[string]string{
+	"raw":  "",
+	"day":  "day",
+	"week": "week",
+}
+
+// HistoryHandler price series of one item
+// GET /api/items/:id/history?period=day&from=2018-01-01&to=2018-03-01&format=csv
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var err error
+	var hq store.HistoryQuery
+	var ctx = make(map[string]interface{})
+
+	query := r.URL.Query()
+
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+		return
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = "day"
+	}
+	field, ok := historyPeriods[period]
+	if !ok {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "period should be one of raw, day, week"})
+		return
+	}
+	hq.Period = field
+
+	// default the whole history, to is inclusive
+	if s := query.Get("from"); s != "" {
+		if hq.From, err = time.Parse(dateLayout, s); err != nil {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "from should be YYYY-MM-DD"})
+			return
+		}
+	}
+	if s := query.Get("to"); s != "" {
+		if hq.To, err = time.Parse(dateLayout, s); err != nil {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "to should be YYYY-MM-DD"})
+			return
+		}
+		hq.To = hq.To.AddDate(0, 0, 1)
+	}
+
+	item, err := AppContext.Items.Get(id)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "item not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	points, err := AppContext.Items.History(id, hq)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		writeHistoryCSV(w, *item, points)
+		return
+	}
+
+	ctx["item"] = item
+	ctx["period"] = period
+	ctx["history"] = points
+	Render.JSON(w, http.StatusOK, ctx)
+}
+
+func writeHistoryCSV(w http.ResponseWriter, item schema.Item, points []schema.PricePoint) {
+	w.Header().Set("Content-type", "text/csv;charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=item-%d-history.csv", item.Id))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"period", "min", "max", "avg", "last", "count"})
+	for _, p := range points {
+		cw.Write([]string{
+			p.Period.Format(time.RFC3339),
+			strconv.Itoa(p.Min),
+			strconv.Itoa(p.Max),
+			strconv.FormatFloat(p.Avg, 'f', 2, 64),
+			strconv.Itoa(p.Last),
+			strconv.Itoa(p.Count),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"honestman/schema"
+	"honestman/store"
+
+	"github.com/go-zoo/bone"
+)
+
+// recentChanges price changes of item detail
+const recentChanges = 10
+
+// ItemHandler one item with recent price changes, cached by ETag and Last-Modified of updated
+// GET /api/items/:id
+func ItemHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+		return
+	}
+
+	item, err := AppContext.Items.Get(id)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "item not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if notModified(w, r, *item) {
+		return
+	}
+
+	changes, err := AppContext.Items.Changes(id, recentChanges)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if changes == nil {
+		changes = []schema.PriceChange{}
+	}
+
+	ctx["item"] = item
+	ctx["changes"] = changes
+	Render.JSON(w, http.StatusOK, ctx)
+}
+
+// notModified set ETag and Last-Modified of item, answer 304 when the client has it already,
+// every crawl or delisting of item moves updated
+func notModified(w http.ResponseWriter, r *http.Request, item schema.Item) bool {
+	etag := fmt.Sprintf(`"%d-%d"`, item.Id, item.Updated.UnixNano())
+	modified := item.Updated.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// If-None-Match wins over If-Modified-Since
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
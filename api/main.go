@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"honestman/app"
-	"honestman/schema"
+	"honestman/metrics"
+	"honestman/query"
+	"honestman/store"
 	"log"
 	"net/http"
 	"strconv"
@@ -16,7 +18,6 @@ import (
 
 	"github.com/NYTimes/gziphandler"
 	"github.com/go-zoo/bone"
-	"github.com/jmoiron/sqlx"
 	"github.com/justinas/alice"
 	_ "github.com/lib/pq"
 	"github.com/rs/cors"
@@ -36,7 +37,7 @@ var (
 	templateForIndex *template.Template
 
 	// clean up
-	clean = func(s string) (qs []interface{}) {
+	clean = func(s string) (qs []string) {
 		for _, w := range strings.Split(s, " ") {
 			if strings.TrimSpace(w) != "" {
 				qs = append(qs, strings.TrimSpace(w))
@@ -56,8 +57,9 @@ func Index(w http.ResponseWriter, r *http.Request) {
 
 //DocHandler document
 func DocHandler(w http.ResponseWriter, r *http.Request) {
+	// written by hand, then the versioned api from its openapi document
 	readme := FSMustByte(AppContext.Debug, "/static/README.md")
-	content := blackfriday.MarkdownCommon(readme)
+	content := append(blackfriday.MarkdownCommon(readme), blackfriday.MarkdownCommon(v1Spec.markdown())...)
 
 	var doc = struct {
 		Authors     string
@@ -72,57 +74,93 @@ func DocHandler(w http.ResponseWriter, r *http.Request) {
 	templateForDoc.Execute(w, doc)
 }
 
-// APIHandler DEMO simple search
-func APIHandler(w http.ResponseWriter, r *http.Request) {
-	var db *sqlx.DB
+// searchQuery filter, sort and page of search from url query, q in the query language
+func searchQuery(r *http.Request) (store.SearchQuery, error) {
+	var q = store.SearchQuery{Page: 1, PerPage: defaultPerPage}
 	var err error
-	var per_page, page, count int
-	var items []schema.Item
-	var where []string
-	var ctx = make(map[string]interface{})
-	per_page = 50
-	page = 1
-
-	db = r.Context().Value("db").(*sqlx.DB)
 
 	pageStr := r.URL.Query().Get("page")
 
 	if pageStr != "" {
-		page, _ = strconv.Atoi(pageStr)
+		q.Page, err = strconv.Atoi(pageStr)
+		if err != nil || q.Page < 1 {
+			return q, fmt.Errorf("invalid page %q, start from 1", pageStr)
+		}
+	}
+
+	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
+		q.PerPage, err = strconv.Atoi(perPageStr)
+		if err != nil || q.PerPage < 1 || q.PerPage > maxPerPage {
+			return q, fmt.Errorf("invalid per_page %q, 1 to %d", perPageStr, maxPerPage)
+		}
+	}
+
+	q.OnSale = r.URL.Query().Get("sale") == "1"
+	q.Unit = r.URL.Query().Get("unit")
+	q.Sort = r.URL.Query().Get("sort")
+	q.Rank = r.URL.Query().Get("rank")
+	switch q.Rank {
+	case "", "trigram", "fulltext":
+	default:
+		return q, fmt.Errorf("invalid rank %q, should be one of %s", q.Rank, strings.Join(store.Ranks, ", "))
+	}
+
+	// available only by default, "0" for delisted only, "all" for both
+	switch r.URL.Query().Get("available") {
+	case "all":
+	case "0":
+		q.Available = new(bool)
+	default:
+		available := true
+		q.Available = &available
 	}
-	ctx["page"] = page
 
 	kwStr := r.URL.Query().Get("q")
-	kwSlice := clean(kwStr)
+	if err = query.Parse(kwStr, &q); err != nil {
+		return q, err
+	}
 
-	for idx := range kwSlice {
-		where = append(where, fmt.Sprintf("name ~ $%d", idx+1))
+	// cursor of the final sort, sort:x of q included
+	if q.Sort == "" {
+		q.Sort = "price"
+	}
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		q.Cursor, err = store.ParseCursor(token, q.Sort)
 	}
-	log.Println(where)
-	log.Println(kwSlice)
-	// handle paging
-	limitoffset := fmt.Sprintf("LIMIT $%d OFFSET $%d", len(kwSlice)+1, len(kwSlice)+2)
-	kwSlice = append(kwSlice, per_page)
-	kwSlice = append(kwSlice, (page-1)*per_page)
-	log.Println(limitoffset)
-
-	// not include limit offset
-	if len(kwSlice) > 2 {
-
-		err = db.Get(&count, fmt.Sprintf("SELECT count(*) as count FROM item  WHERE %s", strings.Join(where, " AND ")), kwSlice[:len(kwSlice)-2]...)
+	return q, err
+}
+
+// APIHandler DEMO simple search
+func APIHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	q, err := searchQuery(r)
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx["page"] = q.Page
+	ctx["per_page"] = q.PerPage
+	log.Println(q.Keywords)
+
+	if q.Filtered() {
+		count, err := searchPage(w, r, AppContext.Search.Search, q, ctx)
 		if err != nil {
 			log.Println(err)
 			ctx["error"] = err.Error()
 		} else {
-			ctx["count"] = count
+			metrics.SearchResults.Observe(float64(count))
 		}
 
-		err = db.Select(&items, fmt.Sprintf("SELECT * FROM item  WHERE %s ORDER BY price %s", strings.Join(where, " AND "), limitoffset), kwSlice...)
-		if err != nil {
-			log.Println(err)
-			ctx["error"] = err.Error()
-		} else {
-			ctx["item"] = items
+		// sidebar filters, facets=0 to skip
+		if err == nil && r.URL.Query().Get("facets") != "0" {
+			facets, err := AppContext.Search.Facets(q)
+			if err != nil {
+				log.Println(err)
+				ctx["error"] = err.Error()
+			} else {
+				ctx["facets"] = facets
+			}
 		}
 	}
 
@@ -131,6 +169,7 @@ func APIHandler(w http.ResponseWriter, r *http.Request) {
 
 // Main main
 func Main(context *app.Context) *bone.Mux {
+	AppContext = context
 
 	Render = render.New(render.Options{
 		Directory:  "/static",
@@ -152,7 +191,11 @@ func Main(context *app.Context) *bone.Mux {
 		httpware.SimpleLogger,
 		httpware.Recovery,
 		gziphandler.GzipHandler,
-		cors.New(cors.Options{AllowedHeaders: []string{"*"}, AllowCredentials: true}).Handler,
+		cors.New(cors.Options{
+			AllowedMethods:   []string{"GET", "POST", "DELETE"},
+			AllowedHeaders:   []string{"*"},
+			AllowCredentials: true,
+		}).Handler,
 		httpware.PostgresDB(context.DB, "db"),
 	)
 
@@ -167,8 +210,39 @@ func Main(context *app.Context) *bone.Mux {
 	// static files && static pages
 	mux.Get("/static/*", http.FileServer(FS(context.Debug)))
 
-	// api
-	mux.Get("/api/search", common.ThenFunc(APIHandler))
+	// metrics
+	mux.Get("/metrics", metrics.Handler())
+
+	// api, latency observed by route pattern
+	api := func(method, route string, handler http.HandlerFunc) {
+		mux.Register(method, route, common.Append(metrics.Route(route)).ThenFunc(handler))
+	}
+	api("GET", "/api/search", APIHandler)
+	api("GET", "/api/suggest", SuggestHandler)
+	api("GET", "/api/items/:id", ItemHandler)
+	api("GET", "/api/items/:id/history", HistoryHandler)
+	api("GET", "/api/movers", MoversHandler)
+	api("GET", "/api/feeds/search", SearchFeedHandler)
+	api("GET", "/api/feeds/drops", DropsFeedHandler)
+	api("GET", "/api/crawls", CrawlsHandler)
+	api("GET", "/api/crawls/:id", CrawlHandler)
+	api("GET", "/api/categories", CategoriesHandler)
+	api("GET", "/api/categories/:id/items", CategoryItemsHandler)
+	api("GET", "/api/products/:id/offers", OffersHandler)
+	api("POST", "/api/watches", CreateWatchHandler)
+	api("GET", "/api/watches/:id", WatchHandler)
+	api("DELETE", "/api/watches/:id", DeleteWatchHandler)
+
+	// versioned api, documented by its openapi document
+	for _, route := range v1Routes {
+		api(route.Method, v1+route.Path, route.Handler)
+	}
+	v1Spec = newOpenAPI(v1, v1Routes)
+	specJSON = v1Spec.mustJSON()
+	api("GET", v1+"/openapi.json", OpenAPIHandler)
+
+	// cors preflight, answered by the cors handler
+	mux.Options("/api/*", common.ThenFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	return mux
 }
 
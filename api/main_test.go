@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"honestman/app"
+	"honestman/schema"
+	"honestman/store"
+)
+
+// newTestServer api on a memory store, close it when done, with items 1 to 5 of RTmart priced 10 to 50,
+// 1 to 3 in category 2 under 1, item 1 crawled again at 8
+func newTestServer(t *testing.T) (*httptest.Server, *store.Memory) {
+	mem := store.NewMemory()
+	context := &app.Context{
+		Search: mem, Items: mem, Runs: mem, Products: mem, Categories: mem, Watches: mem, DeadLetters: mem,
+	}
+
+	root := &schema.Category{Source: "RTmart", Code: "1", Name: "生鮮", Path: "生鮮"}
+	if err := mem.SaveCategory(root); err != nil {
+		t.Fatal(err)
+	}
+	fruit := &schema.Category{Source: "RTmart", Code: "2", Name: "水果", Path: "生鮮 > 水果", ParentId: &root.Id}
+	if err := mem.SaveCategory(fruit); err != nil {
+		t.Fatal(err)
+	}
+
+	crawled := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, n := range []int{1, 2, 3, 4, 5} {
+		item := schema.Item{
+			Source: "RTmart", Url: "/item/" + string(rune('0'+n)), Name: "蘋果 " + string(rune('0'+n)),
+			Price: n * 10, Created: crawled, Updated: crawled,
+		}
+		if n <= 3 {
+			id := fruit.Id
+			item.Category, item.CategoryId = fruit.Path, &id
+		}
+		if _, err := mem.Upsert(&item, store.Observation{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	again := schema.Item{Source: "RTmart", Url: "/item/1", Name: "蘋果 1", Price: 8, Updated: crawled.Add(24 * time.Hour)}
+	if _, err := mem.Upsert(&again, store.Observation{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(Main(context)), mem
+}
+
+// get path of server, decode json into v when given
+func get(t *testing.T, server *httptest.Server, path string, header http.Header, v interface{}) *http.Response {
+	req, err := http.NewRequest("GET", server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range header {
+		req.Header.Set(k, header.Get(k))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return resp
+}
+
+func TestSearchBadRequest(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	other := store.CursorOf(schema.Item{Id: 1}, "updated", false)
+	tests := []string{
+		"/api/search?q=蘋果&page=0",
+		"/api/search?q=蘋果&page=x",
+		"/api/search?q=蘋果&per_page=0",
+		"/api/search?q=蘋果&per_page=201",
+		"/api/search?q=蘋果&rank=bm25",
+		"/api/search?q=蘋果&cursor=garbage",
+		"/api/search?q=蘋果&cursor=" + other.String(),
+		"/api/search?q=" + url.QueryEscape("price:100..50"),
+		"/api/search?q=" + url.QueryEscape("OR 蘋果"),
+		"/api/search?q=" + url.QueryEscape("unit:kg"),
+		"/api/categories/1/items?per_page=500",
+	}
+	for _, path := range tests {
+		if resp := get(t, server, path, nil, nil); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, resp.StatusCode)
+		}
+	}
+}
+
+// linkRe one link of Link header
+var linkRe = regexp.MustCompile(`<([^>]*)>; rel="(next|prev)"`)
+
+// links of resp by rel
+func links(resp *http.Response) map[string]string {
+	found := make(map[string]string)
+	for _, m := range linkRe.FindAllStringSubmatch(resp.Header.Get("Link"), -1) {
+		found[m[2]] = m[1]
+	}
+	return found
+}
+
+func TestSearchPaging(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	type page struct {
+		Count int           `json:"count"`
+		Item  []schema.Item `json:"item"`
+		Next  string        `json:"next"`
+		Prev  string        `json:"prev"`
+	}
+
+	// forward by next links, cheapest first
+	var prices []int
+	var pages []*http.Response
+	path := "/api/search?q=" + url.QueryEscape("source:RTmart") + "&per_page=2&facets=0"
+	for path != "" {
+		var p page
+		resp := get(t, server, path, nil, &p)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d", path, resp.StatusCode)
+		}
+		if p.Count != 5 {
+			t.Errorf("%s: count %d, want 5", path, p.Count)
+		}
+		for _, item := range p.Item {
+			prices = append(prices, item.Price)
+		}
+		if next := links(resp)["next"]; (next == "") != (p.Next == "") || (next != "" && !strings.Contains(next, p.Next)) {
+			t.Errorf("%s: Link next %q, body next %q", path, next, p.Next)
+		}
+		pages = append(pages, resp)
+		path = links(resp)["next"]
+		if len(pages) > 5 {
+			t.Fatal("paging does not end")
+		}
+	}
+	if want := []int{8, 20, 30, 40, 50}; len(pages) != 3 || !equal(prices, want) {
+		t.Fatalf("%d pages of prices %v, want 3 of %v", len(pages), prices, want)
+	}
+	if _, ok := links(pages[0])["prev"]; ok {
+		t.Error("first page has prev")
+	}
+
+	// back from the last page by prev links
+	prices = nil
+	path = links(pages[2])["prev"]
+	for path != "" {
+		var p page
+		resp := get(t, server, path, nil, &p)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d", path, resp.StatusCode)
+		}
+		var got []int
+		for _, item := range p.Item {
+			got = append(got, item.Price)
+		}
+		prices = append(got, prices...)
+		path = links(resp)["prev"]
+	}
+	if want := []int{8, 20, 30, 40}; !equal(prices, want) {
+		t.Errorf("back by prev %v, want %v", prices, want)
+	}
+}
+
+func TestItemHandler(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var detail struct {
+		Item    schema.Item          `json:"item"`
+		Changes []schema.PriceChange `json:"changes"`
+	}
+	resp := get(t, server, "/api/items/1", nil, &detail)
+	if resp.StatusCode != http.StatusOK || detail.Item.Price != 8 || len(detail.Changes) != 1 || detail.Changes[0].Previous != 10 {
+		t.Fatalf("status %d item %+v changes %+v", resp.StatusCode, detail.Item, detail.Changes)
+	}
+
+	etag := resp.Header.Get("ETag")
+	tests := []struct {
+		path   string
+		header http.Header
+		status int
+	}{
+		{"/api/items/1", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"/api/items/1", http.Header{"If-None-Match": {`"1-0"`}}, http.StatusOK},
+		{"/api/items/1", http.Header{"If-Modified-Since": {resp.Header.Get("Last-Modified")}}, http.StatusNotModified},
+		{"/api/items/99", nil, http.StatusNotFound},
+		{"/api/items/x", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if resp := get(t, server, tt.path, tt.header, nil); resp.StatusCode != tt.status {
+			t.Errorf("%s %v: status %d, want %d", tt.path, tt.header, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	var history struct {
+		Period  string              `json:"period"`
+		History []schema.PricePoint `json:"history"`
+	}
+	resp := get(t, server, "/api/items/1/history?period=raw", nil, &history)
+	if resp.StatusCode != http.StatusOK || history.Period != "raw" || len(history.History) != 2 {
+		t.Fatalf("status %d history %+v", resp.StatusCode, history)
+	}
+	if first, last := history.History[0], history.History[1]; first.Last != 10 || last.Last != 8 {
+		t.Errorf("history %+v, want 10 then 8", history.History)
+	}
+
+	resp = get(t, server, "/api/items/1/history?period=day&from=2018-03-02&to=2018-03-02", nil, &history)
+	if resp.StatusCode != http.StatusOK || len(history.History) != 1 || history.History[0].Last != 8 {
+		t.Errorf("from and to: status %d history %+v", resp.StatusCode, history.History)
+	}
+
+	resp = get(t, server, "/api/items/1/history?format=csv", nil, nil)
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("csv: status %d content type %q", resp.StatusCode, ct)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/items/1/history?period=month", http.StatusBadRequest},
+		{"/api/items/1/history?from=2018-3-1", http.StatusBadRequest},
+		{"/api/items/1/history?to=tomorrow", http.StatusBadRequest},
+		{"/api/items/x/history", http.StatusBadRequest},
+		{"/api/items/99/history", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if resp := get(t, server, tt.path, nil, nil); resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.path, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestCategoryItemsHandler(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	type categoryPage struct {
+		Count    int             `json:"count"`
+		Item     []schema.Item   `json:"item"`
+		Category schema.Category `json:"category"`
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		prices []int
+	}{
+		// sub categories included
+		{"/api/categories/1/items", http.StatusOK, []int{8, 20, 30}},
+		{"/api/categories/2/items?sort=price_desc", http.StatusOK, []int{30, 20, 8}},
+		{"/api/categories/2/items?q=" + url.QueryEscape("price:>=20"), http.StatusOK, []int{20, 30}},
+		{"/api/categories/99/items", http.StatusNotFound, nil},
+		{"/api/categories/x/items", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		var p categoryPage
+		resp := get(t, server, tt.path, nil, &p)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.path, resp.StatusCode, tt.status)
+			continue
+		}
+		var prices []int
+		for _, item := range p.Item {
+			prices = append(prices, item.Price)
+		}
+		if !equal(prices, tt.prices) || (tt.status == http.StatusOK && p.Count != len(tt.prices)) {
+			t.Errorf("%s: count %d prices %v, want %v", tt.path, p.Count, prices, tt.prices)
+		}
+	}
+}
+
+func TestCategoriesHandler(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/categories", `[{"id":1`},
+		// nothing of the store, still a list
+		{"/api/categories?source=Carrefour", `[]`},
+	}
+	for _, tt := range tests {
+		var ctx map[string]json.RawMessage
+		if resp := get(t, server, tt.path, nil, &ctx); resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.path, resp.StatusCode)
+		}
+		if got := string(ctx["category"]); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: category %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+const (
+	// defaultWindow of movers
+	defaultWindow = "1d"
+	// maxWindow of movers, history kept is not endless
+	maxWindow = 90 * 24 * time.Hour
+	// defaultMovers of each direction
+	defaultMovers = 20
+	// maxMovers of each direction
+	maxMovers = 100
+)
+
+// parseWindow "7d" in days, or a go duration "12h"
+func parseWindow(s string) (time.Duration, error) {
+	var window time.Duration
+	var err error
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		var n int
+		n, err = strconv.Atoi(days)
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		window, err = time.ParseDuration(s)
+	}
+	if err != nil || window <= 0 || window > maxWindow {
+		return 0, fmt.Errorf("invalid window %q, as 1d, 7d or 12h, at most 90d", s)
+	}
+	return window, nil
+}
+
+// MoversHandler items whose price dropped or rose the most in percent over window
+// GET /api/movers?window=7d&direction=drop&source=RTmart&category=3&limit=20
+func MoversHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	query := r.URL.Query()
+
+	window := query.Get("window")
+	if window == "" {
+		window = defaultWindow
+	}
+	d, err := parseWindow(window)
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	mq := store.MoverQuery{
+		Since:  time.Now().Add(-d),
+		Source: query.Get("source"),
+		Limit:  defaultMovers,
+	}
+	if s := query.Get("category"); s != "" {
+		if mq.Category, err = strconv.Atoi(s); err != nil {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category id"})
+			return
+		}
+	}
+	if s := query.Get("limit"); s != "" {
+		if mq.Limit, err = strconv.Atoi(s); err != nil || mq.Limit < 1 || mq.Limit > maxMovers {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit should be 1 to %d", maxMovers)})
+			return
+		}
+	}
+
+	direction := query.Get("direction")
+	switch direction {
+	case "", "both", "drop", "rise":
+	default:
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "direction should be one of drop, rise, both"})
+		return
+	}
+
+	for _, rise := range []bool{false, true} {
+		key := "drops"
+		if rise {
+			key = "rises"
+		}
+		if (rise && direction == "drop") || (!rise && direction == "rise") {
+			continue
+		}
+
+		mq.Rise = rise
+		movers, err := AppContext.Items.Movers(mq)
+		if err != nil {
+			log.Println(err)
+			Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if movers == nil {
+			movers = []schema.Mover{}
+		}
+		ctx[key] = movers
+	}
+
+	ctx["window"] = window
+	ctx["since"] = mq.Since
+	Render.JSON(w, http.StatusOK, ctx)
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"honestman/app"
+)
+
+// endpoint one route of the versioned api, registered and documented from the same entry
+type endpoint struct {
+	Method   string
+	Path     string // bone pattern under the version prefix, "/items/:id"
+	ID       string // operationId, method name of generated clients
+	Tag      string
+	Summary  string
+	Params   []param
+	Response interface{} // zero value of the 200 json body
+	Errors   []int       // status codes other than 200 and 500, with error body from 400
+	Handler  http.HandlerFunc
+}
+
+// param query or path parameter
+type param struct {
+	Name        string
+	In          string // "query" when empty
+	Type        string // "string" when empty
+	Format      string
+	Enum        []string
+	Description string
+}
+
+// jsonSchema of OpenAPI, a map keeps it short
+type jsonSchema map[string]interface{}
+
+// openAPI document, only what we use of OpenAPI 3
+type openAPI struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       apiInfo                          `json:"info"`
+	Servers    []apiServer                      `json:"servers"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]jsonSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type apiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type apiServer struct {
+	URL string `json:"url"`
+}
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string     `json:"name"`
+	In          string     `json:"in"`
+	Description string     `json:"description,omitempty"`
+	Required    bool       `json:"required,omitempty"`
+	Schema      jsonSchema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema jsonSchema `json:"schema"`
+}
+
+// apiError body of every error response
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// openAPIPath of bone pattern, ":id" to "{id}"
+func openAPIPath(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for idx, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[idx] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// newOpenAPI document of routes under prefix, schemas of bodies generated from their go types
+func newOpenAPI(prefix string, routes []endpoint) *openAPI {
+	doc := &openAPI{
+		OpenAPI: "3.0.3",
+		Info: apiInfo{
+			Title:       app.PackageName,
+			Description: "Items and prices crawled from stores, see /doc",
+			Version:     app.Version,
+		},
+		Servers: []apiServer{{URL: prefix}},
+		Paths:   make(map[string]map[string]*operation),
+	}
+	doc.Components.Schemas = make(map[string]jsonSchema)
+
+	for _, route := range routes {
+		op := &operation{
+			OperationID: route.ID,
+			Summary:     route.Summary,
+			Responses: map[string]response{
+				"200": {
+					Description: http.StatusText(http.StatusOK),
+					Content:     map[string]mediaType{"application/json": {Schema: doc.schemaOf(reflect.TypeOf(route.Response))}},
+				},
+			},
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+		for _, p := range route.Params {
+			op.Parameters = append(op.Parameters, p.parameter())
+		}
+
+		errSchema := doc.schemaOf(reflect.TypeOf(apiError{}))
+		for _, code := range append(route.Errors, http.StatusInternalServerError) {
+			resp := response{Description: http.StatusText(code)}
+			if code >= http.StatusBadRequest {
+				resp.Content = map[string]mediaType{"application/json": {Schema: errSchema}}
+			}
+			op.Responses[fmt.Sprint(code)] = resp
+		}
+
+		path := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*operation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+// parameter of OpenAPI, path parameters are required
+func (p param) parameter() parameter {
+	in, typ := p.In, p.Type
+	if in == "" {
+		in = "query"
+	}
+	if typ == "" {
+		typ = "string"
+	}
+	s := jsonSchema{"type": typ}
+	if p.Format != "" {
+		s["format"] = p.Format
+	}
+	if len(p.Enum) > 0 {
+		s["enum"] = p.Enum
+	}
+	return parameter{Name: p.Name, In: in, Description: p.Description, Required: in == "path", Schema: s}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf go type by its json encoding, named structs become components
+func (doc *openAPI) schemaOf(t reflect.Type) jsonSchema {
+	switch {
+	case t == timeType:
+		return jsonSchema{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Ptr:
+		s := jsonSchema{"nullable": true}
+		for k, v := range doc.schemaOf(t.Elem()) {
+			s[k] = v
+		}
+		if ref, ok := s["$ref"]; ok {
+			// siblings of $ref are ignored, wrap it
+			delete(s, "$ref")
+			s["allOf"] = []jsonSchema{{"$ref": ref}}
+		}
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{"type": "array", "items": doc.schemaOf(t.Elem())}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": doc.schemaOf(t.Elem())}
+	case reflect.Struct:
+		name := componentName(t)
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			// placeholder first, a type may refer to itself
+			doc.Components.Schemas[name] = jsonSchema{}
+			doc.Components.Schemas[name] = doc.structSchema(t)
+		}
+		return jsonSchema{"$ref": "#/components/schemas/" + name}
+	}
+	return jsonSchema{}
+}
+
+// structSchema properties of exported fields by json tag, embedded structs flattened
+func (doc *openAPI) structSchema(t reflect.Type) jsonSchema {
+	properties := make(map[string]jsonSchema)
+	var required []string
+
+	var fields func(t reflect.Type)
+	fields = func(t reflect.Type) {
+		for idx := 0; idx < t.NumField(); idx++ {
+			field := t.Field(idx)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+				fields(field.Type)
+				continue
+			}
+			if field.PkgPath != "" {
+				continue
+			}
+
+			opts := strings.Split(tag, ",")
+			name := opts[0]
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = doc.schemaOf(field.Type)
+			if !strings.Contains(tag, ",omitempty") && field.Type.Kind() != reflect.Ptr {
+				required = append(required, name)
+			}
+		}
+	}
+	fields(t)
+
+	s := jsonSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+// componentName of struct, "Item" of schema.Item, "ItemPage" of itemPage
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+var (
+	// v1Spec document of /api/v1, built once by Main
+	v1Spec *openAPI
+	// specJSON of v1Spec
+	specJSON []byte
+)
+
+// OpenAPIHandler OpenAPI 3 document of /api/v1, for client generators
+// GET /api/v1/openapi.json
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-type", "application/json;charset=utf-8")
+	w.Write(specJSON)
+}
+
+// markdown of document, one section of each operation as README.md does
+func (doc *openAPI) markdown() []byte {
+	var buf bytes.Buffer
+
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	prefix := doc.Servers[0].URL
+	fmt.Fprintf(&buf, "<a name=\"v1\"></a>\n# API %s\n\n", prefix)
+	fmt.Fprintf(&buf, "OpenAPI 3 document at [%s/openapi.json](%s/openapi.json), for generating clients.\n\n", prefix, prefix)
+	for _, path := range paths {
+		var methods []string
+		for method := range doc.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := doc.Paths[path][method]
+			fmt.Fprintf(&buf, "## <span class=\"label label-default\">%s %s%s</span>\n\n", strings.ToUpper(method), prefix, path)
+			fmt.Fprintf(&buf, "%s\n\n", op.Summary)
+			for _, p := range op.Parameters {
+				desc := p.Description
+				if enum, ok := p.Schema["enum"].([]string); ok {
+					desc = strings.TrimSpace(strings.Join(enum, ", ") + "; " + desc)
+				}
+				if p.In == "path" {
+					desc = strings.TrimSpace("path " + desc)
+				}
+				fmt.Fprintf(&buf, "* <span class=\"label label-default\">%s</span>%s\n\n", p.Name, desc)
+			}
+			fmt.Fprintf(&buf, "* 回傳 %s\n\n", doc.fieldsOf(op.Responses["200"].Content["application/json"].Schema))
+		}
+	}
+	return buf.Bytes()
+}
+
+// fieldsOf schema of a component, "Name: a, b, c"
+func (doc *openAPI) fieldsOf(s jsonSchema) string {
+	ref, _ := s["$ref"].(string)
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	properties, _ := doc.Components.Schemas[name]["properties"].(map[string]jsonSchema)
+
+	var fields []string
+	for field := range properties {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return name + "：" + strings.Join(fields, ", ")
+}
+
+// mustJSON document indented
+func (doc *openAPI) mustJSON() []byte {
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+const (
+	// defaultPerPage items of a search page
+	defaultPerPage = 50
+	// maxPerPage items of a search page at most
+	maxPerPage = 200
+)
+
+// searchFunc Search of store.Searcher or store.ItemRepository
+type searchFunc func(q store.SearchQuery) ([]schema.Item, int, error)
+
+// searchPage search one page of q into ctx as count and item, with next and prev
+// cursors in ctx and Link header, return the total count
+func searchPage(w http.ResponseWriter, r *http.Request, search searchFunc, q store.SearchQuery, ctx map[string]interface{}) (int, error) {
+	// one more tell if there is a page further
+	fetch := q
+	fetch.PerPage++
+	items, count, err := search(fetch)
+	if err != nil {
+		return 0, err
+	}
+
+	more := len(items) > q.PerPage
+	var next, prev *store.Cursor
+	if q.Cursor != nil && q.Cursor.Before {
+		// the extra one is ahead, we came from behind
+		if more {
+			items = items[1:]
+		}
+		if len(items) > 0 {
+			next = cursorOf(items[len(items)-1], q.Sort, false)
+			if more {
+				prev = cursorOf(items[0], q.Sort, true)
+			}
+		}
+	} else {
+		if more {
+			items = items[:q.PerPage]
+		}
+		if len(items) > 0 {
+			if more {
+				next = cursorOf(items[len(items)-1], q.Sort, false)
+			}
+			if q.Cursor != nil || q.Page > 1 {
+				prev = cursorOf(items[0], q.Sort, true)
+			}
+		}
+	}
+
+	var links []string
+	if next != nil {
+		ctx["next"] = next.String()
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(r, next.String())))
+	}
+	if prev != nil {
+		ctx["prev"] = prev.String()
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(r, prev.String())))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	if items == nil {
+		items = []schema.Item{}
+	}
+	ctx["count"] = count
+	ctx["item"] = items
+	return count, nil
+}
+
+// cursorOf item as pointer
+func cursorOf(item schema.Item, sort string, before bool) *store.Cursor {
+	c := store.CursorOf(item, sort, before)
+	return &c
+}
+
+// cursorURL the same request at cursor instead of page
+func cursorURL(r *http.Request, cursor string) string {
+	u := *r.URL
+	values := u.Query()
+	values.Del("page")
+	values.Set("cursor", cursor)
+	u.RawQuery = values.Encode()
+	return u.RequestURI()
+}
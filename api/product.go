@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"honestman/store"
+
+	"github.com/go-zoo/bone"
+)
+
+// OffersHandler current price of one product in every store, cheapest first
+// GET /api/products/:id/offers
+func OffersHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	product, err := AppContext.Products.GetProduct(id)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "product not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	offers, err := AppContext.Products.Offers(id)
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx["product"] = product
+	ctx["offers"] = offers
+	Render.JSON(w, http.StatusOK, ctx)
+}
@@ -190,13 +190,81 @@ var _escData = map[string]*_escFile{
 
 	"/static/README.md": {
 		local:   "static/README.md",
-		size:    262,
-		modtime: 1520321125,
+		size:    10440,
+		modtime: 1792229849,
 		compressed: `
-H4sIAAAAAAAA/1JW8MjPSy0uyU3MU3AM8FR4sWrVsxl9XFxcWgo2iQoZRalptkrKxamJRckZSgrJOYnF
-xbZKxclF+Tk5JflKdsFgCRv9RDsuLi6bRIW8xNxUWyWocjuwuLJCcGpicoaCY0Eml7Kygk1xQWIezKCc
-xKTUHAUwqZuSmpZYmlOiZOfuGqKgn1iQqV8MNRykww7sIIJ6C6Gqn81f+mLlouezWl5On/Oyd+vTtdNB
-+l0rrJBNti+0fTGn6cWcOVxcgAAAAP//QWwx3AYBAAA=
+H4sIAAAAAAAA/6RafVPb1pr/n09xxpll7nYg2Gk795aNk+222dveabd3mu7sdHZ2MqotwDe25UgyaW63
+MzLBYGM5dhMwwXaAJEAgvOalRIAgHwadI+kvfYWd55wjIQKbGO4/Hls65znnefs9b76AvpKyoqJmhCz6
+/K9fI+f5c/LwXldX10fosoCGZHEgHrmgiIKcGIqgRFpQlHhESchSOq1KkSvX6YvLfcKV4+vzg4Oiop62
+gb05sSOliplTln+tipkTa4dSiirJd05Z/hV7c2JHRhoWZeWUDd/SFyfWD4hi8rTl/w7PT6xOyMLt9GnL
+v6AvTq4XVHFQklPiqXuClyf2SQMDp3PxHX1xYv1tQU0MnXrIf7E3J3YMx05ZDFYxHKNru/qEXKpvOIbs
+kV3SLtvlEmmv2c1RsBzP1Jnt2JMv0H9/lxOzsJE0xq297f/5A9/YJ+XErJBLXfybImX/GdmTT+zJOc/U
+cW3Tnly2DsbYE1wfdZ7POssa0E6kU2JW9cyWtbdoGRUEpJDdHHXebOKD3+ztdaIXLKPqbEwcaiNdXV2X
+BZQVMmI8wq32Cr36BXRdFBJD6PNcquvCBXRZyQlZn9e08JOYRvSzNykOCPm0Grny52s/0KP6FG7jsOMK
+9YsP7r3FV5O5RWflid0cdafbbvV3vD5NpTRLXk05SxXLqJDGTkj8t/LiqWbNyLB9lJmOLqEIaZHfI4Zw
+7TkpH9jlHXx3xzLW7eYonhrDDwqd8aNIsspJ5eRUQkT47g6ZN639e7i05a4+9MySO193ltc9s3yoFeia
+G0lRSfCF7upDXNqy9u8daoV8NqXe4FQaG9Z+lS3xzJI9+pi8msKlBdJ4gzd23PGa3Rwl9+6TtoYPdEY6
+n0sKqphEpK05b38jrdeksXWoFZKylEPuDKXV1vBC81AryGJaHBaycNsHBVyvOqXxQA2krdktw3n7W2f8
+DwgJUVW4BKIIrG25ZG9MI/aiMyKykL3JSSgJSRbBhu2NafJqyjObqpwalIXMKVeFVS3DMk28uxSWs2fq
+A/l0WhV/VhEuLpPGOHn+xH79hNy7j+tVzyyRxhtnZRYf6Iwmrq+Cy4Zs0VmZdbYn8ELT2Z6gSix7ZgsX
+t629BtZM3JrFI6/QJ9FoZ+yBXjl7zNiYdtEgXGWzhmLR6CDTYSbNHuHiGq6Oe2aZlBool+APtYpnls9o
+n8KwkEoLP52wd2uvitvLZHbbMiYCku8IMcrX4jcvwSFntz1TF9JpkKl7d7mz83OifCMnDPrHk82aO1+w
+18fIlOGZOnMN9GnUM3Ww5YUmutSpVENU7bU1vHPgzhcALw9mUQy5jQp+VumMUCIvK5LMSTHdArhmwXyo
+/GVxGAjXGtbeUwTHIvvtHnitUbEMzZ0vkFLDMibYd+Dk3n28W7NbBq7rZGbE/r1OZtuWUcWtedLYYtpz
+NN158wpXpjyzBRgCnoPrOhzM7oOOWxnchuKHOAwhJjj6UCsERx9thku8vE/aZTIzAoQpKc/UrZ05MnmA
+28toSBSSooy+SWVvemZJFtPxCBwRYegQj+RkcTjimWU4m0GSPbJrtzZwuYonN/DdHc/UGWYCh+tl0jBR
+TpYyEgMwz2zJ4mA+Lcg+oN2bY5uk7A2AX0QebuL6EiPhmXpOSNy8cUu9g8hmDetFMmW44zXPbN3KC1k1
+pd5BTmkcgSMB98xtSanhLNUAbBlqU1z0TD0EoxCMuYMdaoXAsUipAd+1CmzjGNsKPIVfLewhnqmnBUW9
+oYhiFgAWH+iWoZG1x7i97Lx6iudf2+0KLm0BKs+MuI0HoFWGZCO7JyHLnW5TyNJB8/EjNLYOHpH1BYCp
+3VpX10ccQ4GGvbaE66UgaDIPZJYEm9ZGrf0qHM7FpuOFJixrL+Ny1TObipSXQQlTY3h3+lAr8DzrDsKl
+MffxLMsXAGV8H0RYq4QiFg9WuKpR3krDQjovIrw0gj6NXrz42Wc8T2m9JvcW7cllZgyphNiP7M0Vt2Aw
+Ur7qw7GWKRqYvfZzfziruHor7rRHnHb7fe+6wZTisdOX2OUWXtzuBnOIZ9LdVNZHtvFesoEtxIV0+n0r
+US+ZbduvGohJuP/7HzKCrHLmL38cjb5vc7cPjvFL0W7muHHxzl/+/vXfpFTiq7/kfrz0n6lvvrh48WIo
+c2OJEE/cLlxA4QQIzrJXdu2ZfVBrczIc0dy7+85SAY/v2jXwf+ZBgAhGFZfG8MIzvFXDm6ueqf8BfYT+
+CdnrZXttyZmZs4vP8Pq0++gJ2ZzEW2BMnHPy3CDP1+DM4BCnNM4Ig3MsP8VFHmkPtQIuLuP9J85+GVcn
+8P6TQ61gbz12Vyed/bK9U3BXJyH9o4QZXcQx5dhhEDFetvHitv/W3djAi9ue2bLL45AWl8bRd9+jMN+W
+USWVMbdBY4HbmsHmlDMzh4vPKIhqWKuwrZ7Z7L6Vl9R/OXYgewRbAfcm5lEvIvfuuzMLAf0jeXBLgMXf
+fQ++VGqg//XMsqs9Ba8I3YlrobhtGRquvsK1Tc9sMmuF6zsvxoj5trPYxc3OD140XJO5OiDD7nTg+Nws
+D7UC//2FIMvigJSXOzuFmzM/hEKBvTmK22AN/F0sGvXBov9yPPzryqeh7/HQjz8dfQUUiUWjoEhKFxBv
+ZdczSwAxbEksGr14MbT7chTSo5mRsyZjPvQF7IQQEOtFyAUb41SxOik1wJEez6JU0jObwU6y9ZrMtkMw
+2s/sxJ6cczc20BXEFrCH4WUfd3ZFACn/eoda4cMbAAXPtkGSgxPAaes6rldx7S6ZMlja4plNeotMGlHi
+MQTw2Q/FBPDA8MbVN53nC8d0QMO0KMuSfKzc5E2PoN5kv89TcfpNknOUnNb+W3ty2dGKZP0pxG3TwEXw
+S6bvzmilU5kgl4c8YKGJdw7s9bGjXDZ2LJcFqlw6/Oqe2WQ5BfjogwJEaY7FpZupbJLCvGeWQSsPCnZZ
+95//JAvZJMvLAWlXZt1GxZ1ft1uGvbYEVEaf42IJ3ha3ydpje2QX+ZUTK5Lg1VjVOnjEDiZTBkpI+ayK
+8MQ8WZvwTJ3VXu7qwyB1OB69GANB+AprmDapfPVCa+ocugUaSt8vqeSvXMAh4cE7z2yyq+MNnUy9dl6N
+k8YMcFVfRQlZpMUv3tu1VyssB4OKWk7TpOdBwZ0vHGqFVGZQkRMItxt2efxQK7yb1L0nnUsMCdlBUQH1
+0QIbxaIIEkCKhs7GBE3lddLYCoQHdrBZs9fHGILx7D0l5RWEy1XAfq3CtkOZnhoYQPjNhvsYLMF5OQ+Z
+4sguK9xZ+sRukETsSiAcnsRf+0EYpI73jaCovd9KydRASkwi6+AR4k0BdhUwi9IaLm3RegUKOvfuPmnf
+Zb0CqHCNbfT1QO9/SFmx91tohNH65+uBgGbv9RTNU41J0n7OmD5y/4+jnxyzF9CZ0hcLm4nfn/QthXcl
+/yFj6eNEQ0bzYTo5UU5JSb4F78/Yxj5LbpEs3O5BSeFOD7otijeP/Dop3GFSZOs4z5lUtgdlhJ97kDA8
+2IPAnnqYW3V2jwFZyvBbOL+/wc8qZHqRtOfQjz/++GPvt9/2fvllZ3RUiVOBYvPR3AkqEEppcLOntvHC
+Ci/sOrifJGcEH/CgM0ktIqEMHwkGnp6md18vV5ms4yDObuA3fika+1NvNNYbjXUz+vGEMhw2E96U9q2E
+taLPYSQZ3sM+g2HcTmWT0m2+hWxOOuZdv+rRY8lwg+RQK/wxScURuzSEXG3GfTzro4UfAD6LJjs7NpmS
+xYSakrL8ZAi00H1hZsZQAErzlCIePSbmS1ZTc038JKlDCBdXHK3Y2aksG+RHsl6Ps/ISAHN3+ox9Jj/H
+OUGMJlC4NoGL23i9zvKpcMMplGGVzxGDaRlPGju4/tvp8fhSKB7HWJLIfRdkrHChAq6HC36oWKjaWSMJ
+l6v4zUvSLjO8tl9rrj4a4kK3Dh4xOLRnDnBpjGxOsioewtPCs1BIaLGQAE0MupdFMTwxbxkTCLwDhc+F
+3orfoggiwAfCRE6UE2JWRe/cBxBgq0amDMvQrP0qzSRaCoVzuMzxQ3l8CVyaOdJV5hzxPya7A3uNgxS7
+mSHFWZkR9mQ2LvIdmQ6JuthEA8Er5DZeOBsanll2lkfcxosjtX2uShno4zJ8kBXaBUHfX7+OLl2MBmK0
+9haZGP1AB6sCJ3S1p7jE+23ufAHl5bRn6jwHAKZpGuCZJTGbSEtKXhapVHxr+TSK7PWxrjNiDrCl8Eqf
+GynYHKm38VYF0Hm2zQwyaNKHreMWwm+L+DHU4Swdh7Ae6h0gPz0vQT4O2oZ2pGVU3cKKPbnMfSjQW/gy
+obztQyuOdzN8kJYV5XzCABMJo3B4GhF2IsYxYlYWFKuhAgoF0mAGGUijhevQkOPp4ViVnzAzAuZAUzLL
+0KgyT7BOL+dbdix5jNkjO+aDTN+Q2fjyHBEpweeeR3ZBDQGSvdIarjUYsiC6DLoze4u4NsF6lcDc7nS4
++UhKdTwxB3vZLsg+bij5REJUOhy8qILiD14YecRU3oPO2CFQVEHN+3zJ+Ww2lR3sQfwuPWhASKXFZA9K
+ZVVRlvM5VUyeHevfnRpcOjk1CHRLBahcBf44KnWzK8bZVc5qyIzeieKkscFzal9zntlkyN3HEzG/EoEp
+wZQBTejGFn4y28dy7r4gjQ6KskOtwOvqKeMUlo7n09wxUmLININH5zHPYHOIy7PnEbg0jcd3Q32oUtCA
+CuyKj7Jo9Ac+cX30KPGgT8nyDuIXgtQbypmcoA4hP7K2coIsZtUbKYr6ljGBXyywbAJQHpJQeB4utunQ
+qYTrq0Em8i5gHgng6jsx7byCpDbDcuKQTE9NiyyjcgocQoMYgJ73igEOaasYAFJIi3xuDL9YRAh614CW
+POxwmNRvIVzbxGNVa28x6EayDv3/I4W+GLv61RPd8yMj5H++8A2Q/eXiHMaXk6VkPqFyiUn8nxtHIgtD
+fHv5yF7oUCpIkxDbCOOpyRehMXwr6LcgfhAYjrNUCUdmkAJ0SGhHJpgtQzJIh0tBUwbXS9b+78dkFtw+
+1sduEBaR/38TX0b8XyadC+mv313nUuKkQoJhqRPPg0BbwBiMuOhoANGJAYhjapv/N8GouDNVy5iEtGH1
+IfTRVUEeFLlqaX9Ba9pzi8Af3Q0ddRaEPjhPYFMCGHxSsZ6cMPBZAJ0C4NqEvfXY3um0xhgSslkxzVm/
+Lf40JEkwvRSSSVlkGSIurrmNimXskRUIjGhIVXN98KEge/slfqR5pk5lCVUr+FjxGTyvTaC0lBDSQ5JC
+J7Hk3iKuTbO2i5JRc8cPQWJGSKX5dD4twRgfarLNVfZDJ8aas7J0lI7h1iwZL7N5F1Klm2KW1nDt5aBb
+xSYCLKqDEKnmAB1qDbJdYgp27+67bc1ZKvhm90uEKzvSj2I9KBJWYqQfffZZD4pwkUX6UQT4iPSgCGcE
+HmXEfxV/FjK5tHgxIWUivx5RpmqHJTwhZEOZyMlTPo0eP4Vr5Z2DqAb6+/pCp/WB8uDIs4EEN/93IzH3
+geIzvLEDGRPNkpgVgzk3HtDWzI2spNI+FjfL+irilwQaTLpMQZ6p8+ba53l1SJJTfxegOu9H/yYKsiij
+X+iqX2n5f5V+j/NHnqnTL2BG9toSKLeus+vBJH79ITQHW7Pok+gnHfL+5bVvrv1w7b3sh+0kuACu6+jP
+137o+r8BAIIZghXIKAAA
 `,
 	},
 
@@ -283,48 +351,62 @@ uPS6dVSpJD5YEDx/nfpvvEuxfhLK8M3bFYQnEZvzKknJxlzO7I0/9D8Cd3f9JwAA///Lj1MfNhUAAA==
 
 	"/static/index.html": {
 		local:   "static/index.html",
-		size:    6178,
-		modtime: 1520320984,
+		size:    9324,
+		modtime: 1792229849,
 		compressed: `
-H4sIAAAAAAAA/8xZ3XLbuPW//uspjrn5L+2JKcp2vPHSpHbTxGt7d2M7sdf7kcl0IBKkYIMADYCyVI8u
-etMX6PS6M53pRR9sO32MDgCSohQq67Q39Y2Ig3N+5ws455AON16dv7z6+eIIxiqnw15Y/2CUDHthjhWC
-sVKFh+9KMomcmDOFmfLUrMAOVKvIUXiqfC16CPEYCYlVVKrUO3DA70L5yfvhhfeS5wVSZETbQKdHEU4y
-7NRSDOU4ciYE3xdcqBbjPUnUOErwhMTYM4ttIIwogqgnY0RxtLMCkmAZC1IowlkL54QzLFWOmOZWRFE8
-bEihbwkdHky9hEmvEDjFKh57Gk5w2sLlBpASdgsC08hpszswFjiNHN9HN2jazzjPKEYFkf2Y548RSzlT
-cp2cYXK7mfxYyq9SlBM6iy6unr7mjLtGkSvVjGI5xli5oJMbuSansZTukkELvtocHRYZ+H6OpnHC+iPO
-lVQCFXqhVTYEf6+/13+uIRe0fk5YP5bSAcIUzgRRs8iRY7R38Mz73fXPhFyefoO/20mO82/fvridxeXJ
-i5O32d7uef5DfH//nLO9tz8n2bNr9PQiv7ySf/C/++JgMkqObsbPSgdiwaXkgmSERQ5inM1yXkrnv3RI
-B9ZD91jyHPvP+s/7A+NTm/wxt+4n6U/FXfHLL9dvjr/74urFeP/imh6fp2/OTi75q93p6Ojpm9uL6csX
-39CzIzzhRyd7l3Qgyeg6Pn9zzc4+5pY94SBFvHCj44wZmk/JSPo3dyUWM3+vv9vfqRbG+BvpDEPf4q0B
-fmzCb1bzfdMZl6t4//QNGQ12n99NZjeXr9OTm/PX6PvbtPzxevrL9IcL9vLbF8/pbv7yx7PT4vjL/Pjl
-q4P747PT+OLV86spWh+XNX74vrb4RiaYkonoM6x8VuT+pMRLIhueBydXr7/fBzkmOSCWwFssC86S/o2E
-06MDkGWhqxPwtGLEFOeYKWmYc5wQBDqyBEvwPAv5jqRAFZwewZfvhz2AzghzKftVlHVgTcJ0ld2XYzLx
-98zRa9YrKfsESNG44+/0n/V3G0LHQdh4h1lC0vfaj15oLo/WNeLJDB56AADmHtgaE4B7cQWmymyDxIKk
-hz2AeS/0K8HQrxqNlh8acfPXC+uqrXih9aPFZsjQBGKKpIwchiYjJMD+eAlOUUlVvUzJFCeeAegtoDVC
-QhoEXbARYVisMq0yVqDa3m5mI4BW2EdCH4EJkbrPeVNZ15jPnHabQevgRqVSnK1gKp5lumkmSCFPIZFh
-FTn9ajPmlKJCNtuGV7tZkYediowyWaBGFYk580ZImNwXiP0viIW+DUdXovyETH4jgXUIYDVSXdrCkrai
-XoswNFkTwJCSYYjqBr2S3NCnpFOJX9JPUl4/CpKN1XpLVs6Ljq7Tsi7hsTMMG64UQYq8uxJLPRp5MREx
-NRWQmHsXdtv+CEU5IlTxQGEhZuO+Kr7ONMXOKqvqMZtgygvs8ZbmNWF7hOq62mVEjcuRqXTWDP9D1Zbn
-t9V2Z6s6eR8hhT5DEz1DIsKAJJGDiqLKXft43pT5iCth5sZGtKtQgcRIxGNzXVp6De/EI2nkYCG4cIZP
-HsA8wfwDI8OUi7wJAxe5RxglDDvwtSxHOVH9QuBJNcheGsrKcTNtsW2fQckELwtnaLrcSpwIK0plR0vz
-uuAsCTYD9MTLeaLnsjsHCopiPOY0wSJy/vnXv//rH3/79S9/+vXPf+y0xfi4orkuoFatda1RPFIMRorV
-bcMZHp/DxqLItBOqbRz2/u+D/LbrTjsYgt83Nq7UIC9PvJ1dp0pVzEumYAiDlkvf8JIl8OQB7OYc4MlD
-gTI89+2vnPdW/VvjUaVEC8FGBDsOfB1TEt+2s3sh8GRzyxl+TtVh4zz8JxpCMNZ1KTnDU2WUZB1Klot3
-d7had0KhEcU1h1ks3wO1MksYmoCgknhwUazIBLsBAFE47yckTSGEwVw7k3IROZuarl8nEzzdAn1rFc7l
-B/U2VMkwJHlWxcCAkTyTIoaNyHUdCMzk1aI7YF9bnZ1nA/1qHPoq6URFENhCZoRLQR2oG/3vRxSxW327
-zZ6ekuZQL7jCc1vDuoGfVIyFILHmXMNlmSQvRSdX6CvROiJ6vRTz0DdpWaR09cLYG+sLfg92jvR1cRzq
-qU8XSD2y1XOSea5SJ2PBKVXcGV6dX5ipKUw5V1gMISzAjJS2tniIkowFEGOmsDh0hp/HvJgd7g52DuBK
-9wE4KRHLQr8YmsttMNrjrFZbjbO6LHpjrDtuAINDQ/Pu8eiWKG/ERYKFJ1BCShnAbjG1+wWXRDfTAMwM
-aokjrhTPA9jZr9mEBV0QCpQkhGUBHNQU402CYy6QBWScYbsVc8pFAPdjojBUKlB8q0swS7xqd0RRfGs3
-eYFiomYfOKEEYrW5FQ/sDQa5tLP60mxfGaiH6gD2G7sTIguKZgGkFFck/eQlRODYIsecljmrIU0vfKiO
-R45ERlgAqFT8sKI1kdhtlFjMAHZgsMSao6n9ABTAwWBguduvGNVb0KLz+DdogizVaT5XOfZ71bA3QQJQ
-UUAEDN/DdYk3rZ2YBuB+horC3bY+Y0pyorCQAbxznzy42+DO3ffVJlIINrcaFwVWpVh4DJBjKVGGA3BP
-MKVc69mogPWfKTgBvHu/IBWGfWeZIAMYLCimXyxRWJkHsN8imIEgALel604vq5XtLHO7mWM15okMGrNt
-HW/7BaDGRPZN+Y9az09hZ5mjniI2t2pN2w2o7kCPAfU+BdTuLMPGnElOcZ/ybNOtWdxtC3a3tYh+CpuW
-BhtRBK7bBgHQJ6QUFCJwfVQQ385jX91FLjytsOApuJ9roxc0vWqBtG0pBd1qbT3pZ1h9e3l+pje2YVMf
-pi2IhktGWCv1Vt/kdGtltwmSHQEjWLAu8c27MbW1ayGrzDSMjwE0Z3Mtop10KkizWOFrUmI5/9+KsTKH
-KIpgsPURS2V9iIyoXwuuCMwBU4k/5rHGKXSxOGVqswNwa+nUdwVjTWj0ZV8bGVMJ6sjoxUcQ22fKHJrW
-7nyxmC9f9t58S3fg5guPb3t56Nt/Bfw7AAD//9t5lY4iGAAA
+H4sIAAAAAAAA/9RZT4/cRnY/pz/FEy2IPdCQ7JmRVnKLbFuRZY3slWYkjbVrC4JRTRbZNVOs4lQVe7oj
+9WGxwCIL5LYIcss/IIccco+DfJx4N/kWwSuS3WT/GdvaLJJIgxnWq1e/95f1XhXDG5+dPDr7+vQxTEzO
+R72w+UNJMuqFOTUEJsYUHr0s2TRyYikMFcYz84I6UI8ix9CZCXDpA4gnRGlqotKk3n0Hgm0ov/S+eug9
+knlBDBvzNtDTxxFNMuo0qwTJaeRMGb0qpDItxiuWmEmU0CmLqWcH+8AEM4xwT8eE0+hgDSShOlasMEyK
+Fs6xFFSbnAjkNsxwOlqSwqAibLFg5iVCe4WiKTXxxEM4JXkLV1pAzsQFKMojp83uwETRNHKCgJyTmZ9J
+mXFKCqb9WOY/ZlkqhdG71lkmdztTEGv9SUpyxufR6dntZ1JI1wpytZlzqieUGhcwuJFrYxpr7XYUWvE1
+6mBg9TAIcjKLE+GPpTTaKFLgIJZ5sCQER/6Rfw9VWNH8nAk/1toBJgzNFDPzyNETcnT/jvfnr79m7NXT
+z+mXB8mT/IuXDy/mcXn88PhldnR4kn8VX13dk+Lo5ddJduc1uX2avzrTfxF8+bP703Hy+Hxyp3QgVlJr
+qVjGROQQIcU8l6V2/kiD0LEeuaJa5jS449/zB9amNvk6s66m6S+Ly+Kbb16/ePLlz84eTu6evuZPTtIX
+z49fyc8OZ+PHt19cnM4ePfycP39Mp/Lx8dErPtBs/Do+efFaPL/OrCrDQat4ZcaWHLN5F3A21sH5ZUnV
+PDjyD/2DemCVP9fOKAwqvB3APzbg5+vxPt/ql7P47tMXbDw4vHc5nZ+/epYen588Iz+/SMtfvJ59M/vq
+VDz64uE9fpg/+sXzp8WTj/Mnjz67f/Xk+dP49LN7ZzOy2y877AgC1PhcJ5SzqfIFNYEo8mBa0s6SG54H
+x2fPfn4X9ITlQEQCL6kupEj8cw1PH98HXRa4O4FMa0bKaU6F0ZY5pwkjgJ5lVIPnVZBvWArcwNPH8PHb
+UQ9gq4el1n7tZXSsDRjusnf1hE2DI5t6y/FayH4CpFqaExz4d/zDJWFLItx4Q0XC0rdoRy+0Lw/KGstk
+Du96AAD2Paj2mCG4p2dgd5l90FSx9EEPYNELg3phGNSFBtcjTv2vFza7tpEFyieryVCQKcScaB05gkzH
+REH1x0toSkpummHKZjTxLEBvBQ0AYcKWCLhhEyaoWmdaZ6xBUd/tzPg/JGvsY4UpMGUa65w3080e85HT
+LjNkF9y4NEaKNUwjswyLZkIM8QxRGTWR49eTseScFHo5bXkjZ0ke9daFNP9CXZClKBZL4Y2JQt8j/f/C
+sjCo3LHFWWGQsOkPBLBxQZMe17okLHmzDtOtXiLIdCs3QMjZKCR1cIO14IYBZ1uFBCX/ScKbR8Wyidmt
+SWsxcqN3nZZ2iYydUbjkSgmkxLssqcbWyIuZirndAZl970LOPlBQThg3cmioUvOJb4pPM6RgG7Ipnoop
+5bKgnmxJDj5YdLPbZcxMyjGKDCo1gk3RFc8Pi90erTrzriGFgSBTbEUJE8CSyCFFUceunZ7nZT6WRmGo
+etvTt96oQFOi4ol9y5acNe/UY2nkUKWkckY334F9gsWGkmEqVd4g47PHBGeCOvCpLsc5M36h6LRuZF9Z
+eR1hAFjDOvpZlEzJsnBGtsoBdPiZKEpTtZb2uOB0Fi4b6KmXywT7sksHPrVrrAplllFtHOBMm8jRzZCU
+RsYyLzg1NHJkmjpQcBLTieQJVZHz+7/7p//853/8/q9/8/3vfrVmAHqMGIKANioN5joXQCjtoQGmXipV
+5GhgAmpuJoV2YDglvKSRo31rGDpe+xdMJBBF4NoS4MIn4H7/u1/94bd/5cIQXBeDUuFuqBU0enVnKo/b
+SK75tykTlXOrAC7dOzYCxkY0xdEZPTmBG6uttMFAuRiJUe/PNrK4ftxIWSWvlu5q02PJvTzxjpw6H1MS
+U6Nbnm0zF0RQDvb3UsfOOl/LUsUUbt2CDsHnVGRmAiMYtKC3gNuazUTmjDAR/u1vWpZtrkC/N2ncYrGl
+vSndm7weMzR3mgxJMUM6ujrwacxZfNF+rT5n3FDVdyvz3H1IfZtHe2uC12rlmNjz8c13kPqxLIXBTLJF
+05IsBCy6qrfbp05ktw1/UmxiYmgm1bwVnYb0IfH5y9/81z/87ZpCf9r4NNpeF6GG5/9pjArFOq+PHX9I
+dH793e///t/XVPnTRseqel1oLMP/RFz+NwIjxbeacHptDP4oj+72G8p198E9cH+8y7pKN577w2+/+/7X
+3/3Hv/7Lh3qtHjQjtH1YC68SFktnXVI+tsWzHhwcukvdW91PtSl2ffq5LEUCN99BHXWAm+8KktFFUP3V
+q5iHBIaVY92AFCxIKU10UPVcn1xGLtwGKmKZ0K9ePsUrTCmoMP3LPQfsZWHkPDQyB1y12WoqrVd9Zm+9
+fO8o2LVZWDm2RfRU0Wl/zxnd4ubBsqzDTwQX2LdsAX9OZ8aCZ1vANwO3cnhoyJjTRqodtMKB82snfsQz
+ahn5dy6JDZtSdwiAtdVPWJpCCIPFcp/oIx0vfRM628OKi+N2l9GgJqOQ5VltKTL5LM+0iuFG5LoODO39
+SIvugL1PjpyDOwO8wA4Dk2xFXWaKXVwq7kBzHP92zIm4wPfGzuFdxgKagTR0gfv9LuCbNaPd3BY7uSqm
+qn3YwhUGRi1jVY07Pg8DG5ZVALe8lKvHquEPlLyyzWcvDHLCxAgvarB7xluWZj+yz3Ucdawk50Y6o7OT
+U7S5F6ZSGqpGEBZgb4Gqrwce4SwTQ4ipMFQ9cEa3YlnMHxwODu7DGR7d4LgkIguDYmQ7VYvRvoH6yMii
+voHCk4w3oXhIHsIAr5wAvCs6vmDGG0uVUOUpkrBSD+GwmFXzhdQMu/Eh2GujijiWxsh8CAd3GzZ78m4T
+CpIkTGRDuN9QrDUJjaUiFaCQglZTseRSDeFqwgyFWgSJL7DdFIlXz445iS+qSVmQmJn5hhFGEdGoW/PA
+0WCQa1y26F7H1QriPdgQ7i71TpguOJkPIeW0JuGTlzBF4wo5lrzMRQOJ4a4hcaAyJob2/PWgpi09cbgU
+AhZ9CAcw6LDmZFZ9sxnCwcFgUMzWrwXrm8vVaTE4J1NSUZ3lJyan+sY06k2JAlIUEIGgV/C6pP1KUcqH
+4H5EisLdt2O86c2ZoUoP4Y178x1WwIX7tp4khkB/b2mjoqZUK5MBcqo1yegQ3GPKuUQ5N2pg/MG3EXFr
+OPzB0jKEgy5BD2GwotiC1KHgXownxBUFN+QuxZ7ru6SqVg5BlJyvqK1zale1euKM5VStL7pE5HqEoQdY
+VJM5NROZ6OHSKVWFaHsNwEyY9tFQiFrPt+Ggy4GHejyo9i0PGr1XM9TCAKri9mPQvevR0YFb0KuLjS5+
+EACxWVRVfNCGKAOpkjmYCYWUKW0ALdqq0S4tXHeb+CoEG/KvCDNWWKmpAo37mpkXTGRAIJf4YWHJHXNK
+FMZQlrWp7bjudbVpT0EEmppmZX8PolFLCQCWQoV36RvFcmTAuwy3reo6MF6GQARv3nY4qteoRWq31zf9
+jJovXp0871fNVo20u8+y8i739qGPlyQbam9VCDkbErx/31VwsXLSYh8OB4MtgaqaZuinjPJkH+zxbS1m
+acUiU/uhZw6ciKwkGd2Hy1IamsDVhIpqJUyIBl2QeJVC6G0759tu5iTtu+DuwSiCQdfhlgkicB3sQ6vR
+bRz1Nr1rPXHZvCaXcBtcwFXWChwNlxjdNc2L0d/iiuqtgn5cKi1VW7lYCi059bnM+m7D6O7X0vd6m5kF
+N7akFG7lpeJoY5URP9B4r8NXAjbVw/8VLv6+De6timcXbo2wI2/bxpaKt8Vbhez+DNFqG+0me6n47gxG
+/W3KWpB1GzZErFg7fIvexor6NFUvqUfv39vNf5Mbd+QG3j6/f981p+bDvbXhs8+bfEuLbLHbaZGdbaDs
+YDsf7rUaInhGzMSPKeP91vKg1oSqb5Fv7xqnLNXCyr1TK5xcOg0HHbYFUK7pD61983b7fGPx4Bot26mG
+OrQtWuz5KWG8359N1K69sMkT5PGrD7ua4paLN0LrtCbn3r+3U9oQU+ozOjM7dsxuk9Bb7PV6rW/FQXXe
+CIOJyfmo998DABL5X5FsJAAA
 `,
 	},
 
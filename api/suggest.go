@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"honestman/schema"
+	"honestman/segment"
+)
+
+const (
+	// defaultSuggest suggestions by default
+	defaultSuggest = 10
+	// maxSuggest suggestions at most
+	maxSuggest = 20
+)
+
+// SuggestHandler names and brands completing what user typed, best first
+// GET /api/suggest
+func SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	limit := defaultSuggest
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+	if limit > maxSuggest {
+		limit = maxSuggest
+	}
+
+	// same words as item.tokens
+	text := segment.Index(r.URL.Query().Get("q"))
+	suggestions := []schema.Suggestion{}
+	if text != "" {
+		found, err := AppContext.Search.Suggest(text, limit)
+		if err != nil {
+			log.Println(err)
+			Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if found != nil {
+			suggestions = found
+		}
+	}
+
+	ctx["suggest"] = suggestions
+	Render.JSON(w, http.StatusOK, ctx)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+// v1 prefix of the versioned api, routes and openapi document come from v1Routes
+const v1 = "/api/v1"
+
+// searchParams query parameters of item searches
+var searchParams = []param{
+	{Name: "q", Description: "keywords and filters of the query language, see /doc#query"},
+	{Name: "page", Type: "integer", Description: "page from 1, ignored with cursor"},
+	{Name: "per_page", Type: "integer", Description: "items of a page, default 50, at most 200"},
+	{Name: "cursor", Description: "next or prev of the last page, the same sort"},
+	{Name: "sort", Enum: store.Sorts, Description: "default price"},
+	{Name: "sale", Enum: []string{"1"}, Description: "1 only on sale"},
+	{Name: "unit", Enum: []string{"g", "ml", "pc"}, Description: "only items of unit"},
+	{Name: "rank", Enum: store.Ranks, Description: "score of hits, default trigram"},
+	{Name: "available", Enum: []string{"1", "0", "all"}, Description: "1 available only (default), 0 delisted only, all both"},
+}
+
+// idParam path parameter of ids
+var idParam = param{Name: "id", In: "path", Type: "integer"}
+
+// itemPage one page of items
+type itemPage struct {
+	Count   int           `json:"count"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+	Item    []schema.Item `json:"item"`
+	Next    string        `json:"next,omitempty"` // cursor of the next page
+	Prev    string        `json:"prev,omitempty"` // cursor of the previous page
+}
+
+// itemSearch as ItemsHandler answer, facets when asked
+type itemSearch struct {
+	itemPage
+	Facets *schema.Facets `json:"facets,omitempty"`
+}
+
+// itemDetail as ItemHandler answer
+type itemDetail struct {
+	Item    schema.Item          `json:"item"`
+	Changes []schema.PriceChange `json:"changes"` // newest first
+}
+
+// itemHistory as HistoryHandler answer
+type itemHistory struct {
+	Item    schema.Item         `json:"item"`
+	Period  string              `json:"period"`
+	History []schema.PricePoint `json:"history"`
+}
+
+// sourceList as SourcesHandler answer
+type sourceList struct {
+	Source []schema.Source `json:"source"`
+}
+
+// categoryList as CategoriesHandler answer
+type categoryList struct {
+	Category []schema.Category `json:"category"`
+}
+
+// categoryPage as CategoryItemsHandler answer
+type categoryPage struct {
+	itemPage
+	Category schema.Category `json:"category"`
+}
+
+// moverList as MoversHandler answer, drops or rises only when asked by direction
+type moverList struct {
+	Window string         `json:"window"`
+	Since  time.Time      `json:"since"`
+	Drops  []schema.Mover `json:"drops,omitempty"`
+	Rises  []schema.Mover `json:"rises,omitempty"`
+}
+
+// v1Routes every route of /api/v1, in the order of document
+var v1Routes = []endpoint{
+	{
+		Method: "GET", Path: "/items", ID: "listItems", Tag: "items",
+		Summary:  "Search items, every available item without q",
+		Params:   append(searchParams, param{Name: "facets", Enum: []string{"1"}, Description: "1 count facets of every hit"}),
+		Response: itemSearch{},
+		Errors:   []int{http.StatusBadRequest},
+		Handler:  ItemsHandler,
+	},
+	{
+		Method: "GET", Path: "/items/:id", ID: "getItem", Tag: "items",
+		Summary:  "One item with its recent price changes, ETag and Last-Modified from updated",
+		Params:   []param{idParam},
+		Response: itemDetail{},
+		Errors:   []int{http.StatusNotModified, http.StatusBadRequest, http.StatusNotFound},
+		Handler:  ItemHandler,
+	},
+	{
+		Method: "GET", Path: "/items/:id/history", ID: "getItemHistory", Tag: "items",
+		Summary: "Price history of one item",
+		Params: []param{
+			idParam,
+			{Name: "period", Enum: []string{"raw", "day", "week"}, Description: "aggregate, default day"},
+			{Name: "from", Format: "date", Description: "YYYY-MM-DD inclusive"},
+			{Name: "to", Format: "date", Description: "YYYY-MM-DD inclusive"},
+			{Name: "format", Enum: []string{"csv"}, Description: "csv download instead of json"},
+		},
+		Response: itemHistory{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		Handler:  HistoryHandler,
+	},
+	{
+		Method: "GET", Path: "/movers", ID: "listMovers", Tag: "items",
+		Summary: "Available items whose price dropped or rose the most in percent over window",
+		Params: []param{
+			{Name: "window", Description: "1d (default), 7d or a duration as 12h, at most 90d"},
+			{Name: "direction", Enum: []string{"drop", "rise", "both"}, Description: "default both"},
+			{Name: "source", Description: "only items of store"},
+			{Name: "category", Type: "integer", Description: "only items of category and its sub categories"},
+			{Name: "limit", Type: "integer", Description: "items of each direction, default 20, at most 100"},
+		},
+		Response: moverList{},
+		Errors:   []int{http.StatusBadRequest},
+		Handler:  MoversHandler,
+	},
+	{
+		Method: "GET", Path: "/sources", ID: "listSources", Tag: "sources",
+		Summary:  "Stores crawled, with item counts and the last successful crawl",
+		Response: sourceList{},
+		Handler:  SourcesHandler,
+	},
+	{
+		Method: "GET", Path: "/categories", ID: "listCategories", Tag: "categories",
+		Summary:  "Category tree of every store",
+		Params:   []param{{Name: "source", Description: "only categories of store"}},
+		Response: categoryList{},
+		Handler:  CategoriesHandler,
+	},
+	{
+		Method: "GET", Path: "/categories/:id/items", ID: "listCategoryItems", Tag: "categories",
+		Summary:  "Items of category and its sub categories",
+		Params:   append([]param{idParam}, searchParams...),
+		Response: categoryPage{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		Handler:  CategoryItemsHandler,
+	},
+}
+
+// ItemsHandler search items, every available one without filters
+// GET /api/v1/items
+func ItemsHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	q, err := searchQuery(r)
+	if err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx["page"] = q.Page
+	ctx["per_page"] = q.PerPage
+
+	if _, err = searchPage(w, r, AppContext.Search.Search, q, ctx); err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if r.URL.Query().Get("facets") == "1" {
+		facets, err := AppContext.Search.Facets(q)
+		if err != nil {
+			log.Println(err)
+			Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		ctx["facets"] = facets
+	}
+	Render.JSON(w, http.StatusOK, ctx)
+}
+
+// SourcesHandler stores crawled, with the last successful crawl of each
+// GET /api/v1/sources
+func SourcesHandler(w http.ResponseWriter, r *http.Request) {
+	var ctx = make(map[string]interface{})
+
+	sources, err := AppContext.Items.Sources()
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// crawl task is named after its source
+	runs, err := AppContext.Runs.LastSuccess()
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	for idx := range sources {
+		for _, run := range runs {
+			if run.Task == sources[idx].Name {
+				sources[idx].LastSuccess = run.Finished
+			}
+		}
+	}
+
+	if sources == nil {
+		sources = []schema.Source{}
+	}
+	ctx["source"] = sources
+	Render.JSON(w, http.StatusOK, ctx)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"honestman/notify"
+	"honestman/schema"
+	"honestman/store"
+
+	"github.com/go-zoo/bone"
+)
+
+// CreateWatchHandler subscribe an item or a search query with a target price
+// POST /api/watches {"item_id": 1, "target_price": 99, "channel": "smtp", "address": "me@example.com"}
+func CreateWatchHandler(w http.ResponseWriter, r *http.Request) {
+	var watch schema.Watch
+
+	if err := json.NewDecoder(r.Body).Decode(&watch); err != nil {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json: " + err.Error()})
+		return
+	}
+	watch.Query = strings.Join(clean(watch.Query), " ")
+	watch.Id = 0
+	watch.LastNotified = nil
+	watch.Token, watch.TokenHash = "", ""
+
+	if msg := validateWatch(&watch); msg != "" {
+		Render.JSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	if watch.ItemId != nil {
+		_, err := AppContext.Items.Get(*watch.ItemId)
+		switch {
+		case err == store.ErrNotFound:
+			Render.JSON(w, http.StatusBadRequest, map[string]string{"error": "item not found"})
+			return
+		case err != nil:
+			log.Println(err)
+			Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	// only the hash is kept, the token is shown this once
+	token, err := newToken()
+	if err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	watch.TokenHash = hashToken(token)
+
+	if err := AppContext.Watches.CreateWatch(&watch); err != nil {
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	watch.Token = token
+	Render.JSON(w, http.StatusCreated, watch)
+}
+
+// newToken random and unguessable, url safe
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken as kept in watch.token_hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestToken from "Authorization: Bearer <token>", or query token for links in mail
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return r.URL.Query().Get("token")
+}
+
+// ownWatch watch of id when the request has its token, ErrNotFound otherwise,
+// a wrong token looks the same as no watch
+func ownWatch(r *http.Request) (*schema.Watch, error) {
+	id, err := strconv.Atoi(bone.GetValue(r, "id"))
+	if err != nil {
+		return nil, store.ErrNotFound
+	}
+	token := requestToken(r)
+	if token == "" {
+		return nil, store.ErrNotFound
+	}
+
+	watch, err := AppContext.Watches.GetWatch(id)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(watch.TokenHash)) != 1 {
+		return nil, store.ErrNotFound
+	}
+	return watch, nil
+}
+
+// validateWatch error message, empty when ok
+func validateWatch(watch *schema.Watch) string {
+	if watch.ItemId == nil && watch.Query == "" {
+		return "item_id or query is required"
+	}
+	if watch.ItemId != nil && watch.Query != "" {
+		return "watch either item_id or query, not both"
+	}
+	if watch.TargetPrice <= 0 {
+		return "target_price should be positive"
+	}
+
+	switch watch.Channel {
+	case schema.ChannelWebhook:
+		// the crawler should not be made to post into our own network
+		if err := notify.CheckWebhook(watch.Address); err != nil {
+			return "address should be a http or https url of a public host for webhook"
+		}
+	case schema.ChannelSMTP:
+		addr, err := mail.ParseAddress(watch.Address)
+		if err != nil {
+			return "address should be an email for smtp"
+		}
+		watch.Address = addr.Address
+	case schema.ChannelLog:
+	default:
+		return "channel should be one of webhook, smtp, log"
+	}
+	return ""
+}
+
+// WatchHandler one watch, token of the watch required, address not shown
+// GET /api/watches/:id
+func WatchHandler(w http.ResponseWriter, r *http.Request) {
+	watch, err := ownWatch(r)
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "watch not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	watch.Address = ""
+	Render.JSON(w, http.StatusOK, watch)
+}
+
+// DeleteWatchHandler unsubscribe, token of the watch required
+// DELETE /api/watches/:id
+func DeleteWatchHandler(w http.ResponseWriter, r *http.Request) {
+	watch, err := ownWatch(r)
+	if err == nil {
+		err = AppContext.Watches.DeleteWatch(watch.Id)
+	}
+	switch {
+	case err == store.ErrNotFound:
+		Render.JSON(w, http.StatusNotFound, map[string]string{"error": "watch not found"})
+		return
+	case err != nil:
+		log.Println(err)
+		Render.JSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
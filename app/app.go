@@ -6,6 +6,9 @@ import (
 	"log"
 	"os"
 
+	"honestman/index"
+	"honestman/store"
+
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	// _ "github.com/go-sql-driver/mysql"
@@ -26,13 +29,26 @@ var (
 	// not use in this DEMO
 	dbPass = ""
 	port   = ""
+	config = ""
+	// search backend of api, "postgres" or "index"
+	search    = ""
+	indexPath = ""
 )
 
 // Context
 type Context struct {
-	DB    *sqlx.DB
-	Port  string
-	Debug bool
+	DB          *sqlx.DB
+	Port        string
+	Debug       bool
+	Config      string // config file path, used by crawler
+	IndexPath   string // embedded search index, maintained by crawler when given
+	Search      store.Searcher
+	Items       store.ItemRepository
+	Runs        store.RunRepository
+	Products    store.ProductRepository
+	Categories  store.CategoryRepository
+	Watches     store.WatchRepository
+	DeadLetters store.DeadLetterRepository
 }
 
 // ContextInit for initialize
@@ -47,9 +63,26 @@ func ContextInit(dburi string, port string, debug bool) *Context {
 		log.Fatalln(err)
 	}
 
+	pg := store.NewPostgres(db)
 	App.DB = db
+	App.Items = pg
+	App.Search = pg
+	App.Runs = pg
+	App.Products = pg
+	App.Categories = pg
+	App.Watches = pg
+	App.DeadLetters = pg
 	App.Port = port
 	App.Debug = debug
+	App.Config = config
+	App.IndexPath = indexPath
+	if search == "index" {
+		idx, err := index.Open(indexPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		App.Search = idx
+	}
 	return App
 }
 
@@ -62,7 +95,13 @@ func init() {
 	flag.StringVar(&dbPass, "dbpass", "", `database password for connection`)
 	flag.StringVar(&port, "port", ":3000", `address for listen default is :3000`)
 	flag.BoolVar(&debug, "debug", false, `Flag for DEBUG, Default is: false`)
+	flag.StringVar(&config, "config", "", `config file (json or yaml) for crawler tasks`)
+	flag.StringVar(&search, "search", "postgres", `search backend of api, postgres or index`)
+	flag.StringVar(&indexPath, "index", "", `embedded search index file, maintained by crawler, searched by api with -search index`)
+}
 
+// parse flags and environment, by NewContext so packages importing app can be tested
+func parse() {
 	flag.Parse()
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
@@ -87,6 +126,18 @@ func init() {
 		port = os.Getenv("PORT")
 	}
 
+	if os.Getenv("CONFIG") != "" {
+		config = os.Getenv("CONFIG")
+	}
+
+	if os.Getenv("SEARCH") != "" {
+		search = os.Getenv("SEARCH")
+	}
+
+	if os.Getenv("INDEX") != "" {
+		indexPath = os.Getenv("INDEX")
+	}
+
 	if os.Getenv("DEBUG") != "" {
 		debug = true
 	}
@@ -96,6 +147,12 @@ func init() {
 		os.Exit(0)
 	}
 
+	if search == "index" && indexPath == "" {
+		fmt.Println("-search index needs -index file")
+		printhelp()
+		os.Exit(0)
+	}
+
 	fmt.Println(PackageName, Version)
 	fmt.Printf("database: %s@%s %s\n", dbUser, dbHost, dbName)
 	if debug {
@@ -110,6 +167,7 @@ func printhelp() {
 }
 
 func NewContext() *Context {
+	parse()
 	dbURI := fmt.Sprintf(" dbname=%s host=%s user=%s sslmode=disable", dbName, dbHost, dbUser)
 	return ContextInit(dbURI, port, debug)
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"honestman/crawler/task"
+	"honestman/notify"
+	"honestman/webhook"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// defaultMetrics listen address of /metrics
+	defaultMetrics = ":9100"
+	// defaultMatchInterval seconds between product matching
+	defaultMatchInterval = 600
+	// defaultNotifyQueue price drops waiting for watches evaluation
+	defaultNotifyQueue = 1000
+	// defaultWebhookTimeout seconds
+	defaultWebhookTimeout = 10
+	// defaultWebhookRetries attempts after the first failed event delivery
+	defaultWebhookRetries = 5
+)
+
+// NotifyConfig delivery of watch notifications, log and webhook always on
+type NotifyConfig struct {
+	Queue          int                `json:"queue" yaml:"queue"`
+	WebhookTimeout int64              `json:"webhook_timeout" yaml:"webhook_timeout"` // seconds
+	SMTP           *notify.SMTPConfig `json:"smtp" yaml:"smtp"`                       // nil to disable mail
+}
+
+// Config crawler config file, json or yaml by file extension
+type Config struct {
+	Metrics       string         `json:"metrics" yaml:"metrics"`               // listen address of /metrics
+	MatchInterval int64          `json:"match_interval" yaml:"match_interval"` // seconds between product matching
+	Notify        NotifyConfig   `json:"notify" yaml:"notify"`
+	Webhook       webhook.Config `json:"webhook" yaml:"webhook"`
+	Tasks         []task.Config  `json:"tasks" yaml:"tasks"`
+}
+
+// LoadConfig read config file, without path every registered task run with default
+func LoadConfig(path string) (*Config, error) {
+	conf := &Config{Metrics: defaultMetrics, MatchInterval: defaultMatchInterval}
+	conf.Notify.Queue = defaultNotifyQueue
+	conf.Notify.WebhookTimeout = defaultWebhookTimeout
+	conf.Webhook.Retries = defaultWebhookRetries
+	if path == "" {
+		conf.Tasks = task.DefaultConfig()
+		return conf, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, conf)
+	default:
+		err = json.Unmarshal(data, conf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if conf.MatchInterval <= 0 {
+		conf.MatchInterval = defaultMatchInterval
+	}
+	if conf.Notify.Queue <= 0 {
+		conf.Notify.Queue = defaultNotifyQueue
+	}
+	if conf.Notify.WebhookTimeout <= 0 {
+		conf.Notify.WebhookTimeout = defaultWebhookTimeout
+	}
+	return conf, nil
+}
@@ -1,14 +1,31 @@
 package main
 
 import (
+	"context"
 	"honestman/app"
 	"honestman/crawler/task"
+	"honestman/index"
+	"honestman/match"
+	"honestman/metrics"
+	"honestman/notify"
+	"honestman/schema"
+	"honestman/store"
+	"honestman/webhook"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 )
 
+// indexSaveInterval between snapshots of the search index
+const indexSaveInterval = time.Minute
+
+// metricsShutdown wait for running scrapes at most
+const metricsShutdown = 5 * time.Second
+
 var (
 	// AppContext hold share object
 	AppContext *app.Context
@@ -16,23 +33,140 @@ var (
 	Tasks []task.CrawlerTask
 )
 
-// RunTasks fire the execution of each task
-func RunTasks(context *app.Context) {
-	// Task name && sleep interval seconds
-	// one day second = 24 * 60 * 60 = 86400
-	// 8 * 60 * 60  = 28800
+// RunTasks fire the execution of each enabled task in config,
+// wg is done after every task returned
+func RunTasks(ctx context.Context, appContext *app.Context, wg *sync.WaitGroup) {
+	conf, err := LoadConfig(appContext.Config)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	Tasks = append(Tasks, task.NewRTmart(context, 28800))
-	Tasks = append(Tasks, task.NewCarrefour(context, 28800))
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		serveMetrics(ctx, conf.Metrics)
+	}()
 
-	for _, task := range Tasks {
-		log.Println("Running", task)
-		go task.Run()
+	for _, c := range conf.Tasks {
+		if !c.Enabled {
+			log.Println("Skip disabled", c.Name)
+			continue
+		}
+		t, err := task.New(appContext, c)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		Tasks = append(Tasks, t)
+	}
+
+	// price drop of watched items
+	notifier := newNotifier(appContext, conf.Notify)
+	task.OnUpsert(func(item schema.Item, result store.Result) {
+		if result == store.Updated && item.Diff < 0 && item.Price > 0 {
+			notifier.PriceDropped(item)
+		}
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		notifier.Run(ctx)
+	}()
+
+	// embedded search index, the api search it with -search index
+	if appContext.IndexPath != "" {
+		idx, err := index.Open(appContext.IndexPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		task.OnUpsert(func(item schema.Item, result store.Result) {
+			idx.Put(item)
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			idx.Run(ctx, indexSaveInterval)
+		}()
+	}
+
+	// item events to downstream services
+	if len(conf.Webhook.Endpoints) > 0 {
+		dispatcher := webhook.New(conf.Webhook, appContext.DeadLetters)
+		task.OnUpsert(func(item schema.Item, result store.Result) {
+			switch result {
+			case store.Inserted:
+				dispatcher.Publish(webhook.ItemCreated, item)
+			case store.Updated:
+				dispatcher.Publish(webhook.PriceChanged, item)
+			case store.Delisted:
+				dispatcher.Publish(webhook.ItemDisappeared, item)
+			}
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dispatcher.Run(ctx)
+		}()
+	}
+
+	// group items of different stores into products
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		match.New(appContext.Products).Run(ctx, time.Duration(conf.MatchInterval)*time.Second)
+	}()
+
+	for _, t := range Tasks {
+		log.Println("Running", t)
+		wg.Add(1)
+		go func(t task.CrawlerTask) {
+			defer wg.Done()
+			t.Run(ctx)
+		}(t)
 	}
 }
 
-// process shut down
-func sigHandler() {
+// newNotifier with sinks of every watch channel
+func newNotifier(appContext *app.Context, conf NotifyConfig) *notify.Notifier {
+	sinks := map[string]notify.Sink{
+		schema.ChannelLog:     notify.LogSink{},
+		schema.ChannelWebhook: notify.NewWebhookSink(time.Duration(conf.WebhookTimeout) * time.Second),
+	}
+	if conf.SMTP != nil {
+		sinks[schema.ChannelSMTP] = &notify.SMTPSink{Config: *conf.SMTP}
+	}
+	return notify.New(appContext.Watches, sinks, conf.Queue)
+}
+
+// serveMetrics expose /metrics for prometheus, shut down when ctx done
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	log.Printf("Starting metrics service on %s ...", addr)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		log.Println(err)
+		return
+	case <-ctx.Done():
+	}
+
+	// let a running scrape finish
+	shutdown, cancel := context.WithTimeout(context.Background(), metricsShutdown)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		log.Println(err)
+	}
+}
+
+// process shut down, first signal cancel the tasks, second one exit at once
+func sigHandler(cancel context.CancelFunc) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan,
 		syscall.SIGINT,
@@ -43,20 +177,30 @@ func sigHandler() {
 		os.Kill,
 	)
 
-	for s := range sigChan {
-		log.Println("Caught", s)
-		break
-	}
-	os.Exit(0)
+	s := <-sigChan
+	log.Println("Caught", s, "waiting tasks to stop")
+	cancel()
+
+	go func() {
+		s := <-sigChan
+		log.Println("Caught", s, "again, exit now")
+		os.Exit(1)
+	}()
 }
 
 func main() {
+	var wg sync.WaitGroup
 
 	// init share context
 	AppContext = app.NewContext()
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// build task
-	RunTasks(AppContext)
+	RunTasks(ctx, AppContext, &wg)
 	// handle process close
-	sigHandler()
+	sigHandler(cancel)
+
+	wg.Wait()
+	AppContext.DB.Close()
+	log.Println("All tasks stopped")
 }
@@ -2,42 +2,275 @@ package task
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"time"
+
+	"honestman/app"
+	"honestman/metrics"
+	"honestman/schema"
+	"honestman/store"
+	"honestman/unit"
 )
 
 var (
 	// Client with timeout
-	Client        = &http.Client{Timeout: time.Duration(time.Second * 15)}
+	Client        = &http.Client{Timeout: time.Duration(time.Second * 15), Transport: &metrics.Transport{}}
 	fakeUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_12_6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/64.0.3282.186 Safari/537.36"
 )
 
+// Listener get every saved item and what Upsert did to it
+type Listener func(item schema.Item, result store.Result)
+
+// listeners called after each upsert, register before tasks run
+var listeners []Listener
+
+// OnUpsert register l for every saved item
+func OnUpsert(l Listener) {
+	listeners = append(listeners, l)
+}
+
 // CrawlerTask interface
 // refresh in certain interval
 type CrawlerTask interface {
-	Run() // main loop
-	Do()  // doing the dirty job
+	Run(ctx context.Context)                            // main loop, return after ctx done
+	Do(ctx context.Context, run *schema.CrawlRun) error // doing the dirty job, count into run
 }
 
-func fetchGet(url string) (resp *http.Response, err error) {
-	req, err := http.NewRequest("GET", url, nil)
+// loop run do every interval until ctx done, each do is recorded as a crawl run
+func loop(ctx context.Context, appContext *app.Context, conf Config, do func(context.Context, *schema.CrawlRun) error) {
+	runs, name, interval := appContext.Runs, conf.Name, conf.Interval
+	for {
+		// FIXME only one go routine here, more advance version to use worker
+		// maybe block from upstream
+		begin := time.Now().Truncate(time.Second)
+		log.Println(name, begin)
+
+		run, err := runs.StartRun(name)
+		if err != nil {
+			// still crawl, only without bookkeeping
+			log.Println(err)
+			run = &schema.CrawlRun{Task: name, Status: schema.RunRunning, Started: begin}
+		}
+
+		err = do(ctx, run)
+		switch {
+		case ctx.Err() != nil:
+			run.Status = schema.RunInterrupted
+		case err != nil:
+			run.Status = schema.RunFailed
+			fail(run, err)
+		default:
+			run.Status = schema.RunSuccess
+			metrics.LastSuccess.WithLabelValues(name).SetToCurrentTime()
+		}
+		if run.Status != schema.RunInterrupted {
+			delist(appContext.Items, run, time.Duration(conf.Grace)*time.Second)
+		}
+		if run.Id > 0 {
+			if err := runs.FinishRun(run); err != nil {
+				log.Println(err)
+			}
+		}
+
+		log.Println("Fetch all pages", name, run.Status, time.Now().Sub(begin),
+			"pages", run.Pages, "inserted", run.Inserted, "updated", run.Updated,
+			"unchanged", run.Unchanged, "errors", run.Errors)
+		if ctx.Err() != nil || !sleep(ctx, time.Duration(interval)*time.Second) {
+			return
+		}
+	}
+}
+
+// complete run fetched every expected page without error and saw items,
+// an empty or short listing is more likely a broken site than an empty store
+func complete(run *schema.CrawlRun) bool {
+	seen := run.Inserted + run.Updated + run.Unchanged
+	return run.Status == schema.RunSuccess && run.Errors == 0 &&
+		run.Expected > 0 && run.Pages >= run.Expected && seen > 0
+}
+
+// delist mark items of run.Task not seen as unavailable,
+// a complete run covers the whole listing so anything older than it is gone,
+// otherwise pages may be missed so only items missing longer than grace
+func delist(items store.ItemRepository, run *schema.CrawlRun, grace time.Duration) {
+	since := run.Started
+	if !complete(run) {
+		since = time.Now().Add(-grace)
+	}
+
+	gone, err := items.MarkUnavailable(run.Task, since, run.Categories)
 	if err != nil {
-		return nil, err
+		log.Println(run.Task, err)
+		return
 	}
-	req.Header.Set("User-Agent", fakeUserAgent)
-	return Client.Do(req)
+	for _, item := range gone {
+		metrics.ItemsUpserted.WithLabelValues(run.Task, store.Delisted.String()).Inc()
+		for _, l := range listeners {
+			l(item, store.Delisted)
+		}
+	}
+	if len(gone) > 0 {
+		log.Println(run.Task, "delisted", len(gone), "items not seen since", since)
+	}
+}
+
+// categories save the category tree of confs, return the leaves to crawl,
+// a single nil for the whole listing when no category configured
+func categories(repo store.CategoryRepository, source string, confs []CategoryConfig) []*schema.Category {
+	if len(confs) == 0 {
+		return []*schema.Category{nil}
+	}
+
+	saved := make(map[string]*schema.Category)
+	parents := make(map[string]bool)
+	for _, conf := range confs {
+		c := &schema.Category{Source: source, Code: conf.Code, Name: conf.Name, Path: conf.Name}
+		if parent, ok := saved[conf.Parent]; ok {
+			parents[conf.Parent] = true
+			c.Path = parent.Path + " > " + conf.Name
+			if parent.Id > 0 {
+				id := parent.Id
+				c.ParentId = &id
+			}
+		}
+		if err := repo.SaveCategory(c); err != nil {
+			// still crawl, items only without category
+			log.Println(source, err)
+		}
+		saved[conf.Code] = c
+	}
+
+	var leaves []*schema.Category
+	for _, conf := range confs {
+		if !parents[conf.Code] {
+			leaves = append(leaves, saved[conf.Code])
+		}
+	}
+	return leaves
 }
 
-func fetchGetBytes(url string) (resp []byte, err error) {
+// eachCategory crawl every category, a failed category count into run and the rest go on,
+// only items of these categories can be delisted by run, a category not saved has none
+func eachCategory(ctx context.Context, run *schema.CrawlRun, cats []*schema.Category, crawl func(context.Context, *schema.CrawlRun, *schema.Category) error) error {
+	if len(cats) != 1 || cats[0] != nil {
+		run.Categories = []int{}
+		for _, cat := range cats {
+			if cat != nil && cat.Id > 0 {
+				run.Categories = append(run.Categories, cat.Id)
+			}
+		}
+	}
+	for _, cat := range cats {
+		err := crawl(ctx, run, cat)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && len(cats) == 1:
+			// the whole listing, counted as error by loop
+			return err
+		case err != nil:
+			fail(run, err)
+		}
+	}
+	return nil
+}
+
+// inCategory put item into cat, nil cat keep the category item already has
+func inCategory(item *schema.Item, cat *schema.Category) {
+	if cat == nil || cat.Id == 0 {
+		return
+	}
+	id := cat.Id
+	item.Category = cat.Path
+	item.CategoryId = &id
+}
+
+// fail count one error into run
+func fail(run *schema.CrawlRun, err error) {
+	log.Println(run.Task, err)
+	run.Errors++
+	run.LastError = err.Error()
+}
+
+// expect count pages of one listing into run, the first page included
+func expect(run *schema.CrawlRun, pages int) {
+	run.Expected += pages
+}
+
+// fetched count one fetched page into run
+func fetched(run *schema.CrawlRun) {
+	run.Pages++
+	metrics.PagesFetched.WithLabelValues(run.Task).Inc()
+}
+
+// parseFailed count one page or item could not be parsed
+func parseFailed(run *schema.CrawlRun, err error) {
+	metrics.ParseFailures.WithLabelValues(run.Task).Inc()
+	fail(run, err)
+}
+
+// upsert save item and count the result into run
+func upsert(items store.ItemRepository, run *schema.CrawlRun, item *schema.Item, obs store.Observation) {
+	// unit price for honest comparison across pack sizes
+	q, ok := unit.Parse(item.Name)
+	if !ok {
+		q, ok = unit.Parse(item.Note)
+	}
+	if ok {
+		item.Quantity = q.Amount
+		item.Unit = q.Unit
+		item.UnitPrice = unit.Price(item.Price, q)
+	}
+
+	obs.CrawlRunId = run.Id
+	result, err := items.Upsert(item, obs)
+	if err != nil {
+		metrics.ItemsUpserted.WithLabelValues(run.Task, "error").Inc()
+		fail(run, err)
+		return
+	}
+	metrics.ItemsUpserted.WithLabelValues(run.Task, result.String()).Inc()
+	for _, l := range listeners {
+		l(*item, result)
+	}
+
+	switch result {
+	case store.Inserted:
+		run.Inserted++
+	case store.Updated:
+		run.Updated++
+	default:
+		run.Unchanged++
+	}
+}
+
+// sleep d or until ctx done, false when ctx done
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func fetchGet(ctx context.Context, url string) (resp *http.Response, err error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", fakeUserAgent)
+	return Client.Do(req.WithContext(ctx))
+}
 
-	httpresp, err := Client.Do(req)
+func fetchGetBytes(ctx context.Context, url string) (resp []byte, err error) {
+	httpresp, err := fetchGet(ctx, url)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -47,7 +280,7 @@ func fetchGetBytes(url string) (resp []byte, err error) {
 	return ioutil.ReadAll(httpresp.Body)
 }
 
-func fetchPostBytes(url string, payloadStr string) (resp []byte, err error) {
+func fetchPostBytes(ctx context.Context, url string, payloadStr string) (resp []byte, err error) {
 	payload := []byte(payloadStr)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
 
@@ -60,7 +293,7 @@ func fetchPostBytes(url string, payloadStr string) (resp []byte, err error) {
 	req.Header.Set("Referer", "https://online.carrefour.com.tw/search?key=+&categoryId=")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
 
-	httpresp, err := Client.Do(req)
+	httpresp, err := Client.Do(req.WithContext(ctx))
 	if err != nil {
 		log.Println(err)
 		return nil, err
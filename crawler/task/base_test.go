@@ -0,0 +1,154 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"honestman/app"
+	"honestman/schema"
+	"honestman/store"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		run  schema.CrawlRun
+		want bool
+	}{
+		{"every page", schema.CrawlRun{Status: schema.RunSuccess, Expected: 3, Pages: 3, Updated: 1}, true},
+		{"pages missed", schema.CrawlRun{Status: schema.RunSuccess, Expected: 3, Pages: 2, Unchanged: 1}, false},
+		{"expected unknown", schema.CrawlRun{Status: schema.RunSuccess, Pages: 3, Inserted: 1}, false},
+		{"no item seen", schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1}, false},
+		{"with errors", schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1, Unchanged: 1, Errors: 1}, false},
+		{"failed", schema.CrawlRun{Status: schema.RunFailed, Expected: 1, Pages: 1, Unchanged: 1}, false},
+	}
+	for _, tt := range tests {
+		if got := complete(&tt.run); got != tt.want {
+			t.Errorf("%s: complete = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// seed items of task "Test" last seen age ago, in category of the same index when not zero
+func seed(t *testing.T, items store.ItemRepository, age time.Duration, categories ...int) {
+	seen := time.Now().Add(-age).Truncate(time.Second)
+	for idx, name := range []string{"a", "b"} {
+		item := schema.Item{Source: "Test", Url: name, Name: name, Price: 10, Updated: seen}
+		if idx < len(categories) && categories[idx] > 0 {
+			id := categories[idx]
+			item.CategoryId = &id
+		}
+		if _, err := items.Upsert(&item, store.Observation{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// available urls of task "Test"
+func available(t *testing.T, items store.ItemRepository) map[string]bool {
+	found, _, err := items.Search(store.SearchQuery{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := make(map[string]bool)
+	for _, item := range found {
+		if item.Available {
+			urls[item.Url] = true
+		}
+	}
+	return urls
+}
+
+func TestDelist(t *testing.T) {
+	grace := 24 * time.Hour
+	tests := []struct {
+		name       string
+		age        time.Duration // since items last seen
+		run        schema.CrawlRun
+		categories []int // of items a and b
+		want       int   // items left available
+	}{
+		{"complete", time.Hour, schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1, Unchanged: 1}, nil, 0},
+		{"partial within grace", time.Hour, schema.CrawlRun{Status: schema.RunSuccess, Expected: 2, Pages: 1, Unchanged: 1}, nil, 2},
+		{"partial past grace", 48 * time.Hour, schema.CrawlRun{Status: schema.RunSuccess, Expected: 2, Pages: 1, Unchanged: 1}, nil, 0},
+		{"failed within grace", time.Hour, schema.CrawlRun{Status: schema.RunFailed, Errors: 1}, nil, 2},
+		{"empty listing", time.Hour, schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1}, nil, 2},
+		{"outside categories", time.Hour,
+			schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1, Unchanged: 1, Categories: []int{1}}, []int{1, 2}, 1},
+		{"no category saved", time.Hour,
+			schema.CrawlRun{Status: schema.RunSuccess, Expected: 1, Pages: 1, Unchanged: 1, Categories: []int{}}, nil, 2},
+	}
+	for _, tt := range tests {
+		items := store.NewMemory()
+		seed(t, items, tt.age, tt.categories...)
+
+		run := tt.run
+		run.Task, run.Started = "Test", time.Now().Truncate(time.Second)
+		delist(items, &run, grace)
+
+		if got := len(available(t, items)); got != tt.want {
+			t.Errorf("%s: %d items available, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// stopRuns cancel the loop once a run is finished
+type stopRuns struct {
+	*store.Memory
+	stop context.CancelFunc
+}
+
+func (r stopRuns) FinishRun(run *schema.CrawlRun) error {
+	defer r.stop()
+	return r.Memory.FinishRun(run)
+}
+
+func TestLoop(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    int // fetched
+		expected int
+		err      error
+		status   string
+		want     map[string]bool
+	}{
+		{"complete", 2, 2, nil, schema.RunSuccess, map[string]bool{"a": true}},
+		// b is only on the page missed
+		{"partial", 1, 2, nil, schema.RunSuccess, map[string]bool{"a": true, "b": true}},
+		{"failed", 1, 2, errors.New("page 2 timeout"), schema.RunFailed, map[string]bool{"a": true, "b": true}},
+	}
+	for _, tt := range tests {
+		items := store.NewMemory()
+		seed(t, items, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runs := stopRuns{Memory: items, stop: cancel}
+		appContext := &app.Context{Items: items, Runs: runs}
+		conf := Config{Name: "Test", Interval: 3600, Grace: 24 * 3600}
+
+		// a seen again, b not
+		do := func(ctx context.Context, run *schema.CrawlRun) error {
+			expect(run, tt.expected)
+			for i := 0; i < tt.pages; i++ {
+				fetched(run)
+			}
+			item := schema.Item{Source: "Test", Url: "a", Name: "a", Price: 10, Updated: time.Now().Truncate(time.Second)}
+			upsert(items, run, &item, store.Observation{})
+			return tt.err
+		}
+		loop(ctx, appContext, conf, do)
+
+		run, err := items.GetRun(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Status != tt.status || run.Pages != tt.pages {
+			t.Errorf("%s: run %s of %d pages, want %s of %d", tt.name, run.Status, run.Pages, tt.status, tt.pages)
+		}
+		if got := available(t, items); len(got) != len(tt.want) || !got["a"] || got["b"] != tt.want["b"] {
+			t.Errorf("%s: available %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package task
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
 	"honestman/app"
+	"honestman/metrics"
 	"honestman/schema"
+	"honestman/store"
 	"log"
 	"strconv"
 	"strings"
@@ -16,7 +18,9 @@ var (
 	// onece 35 their rule
 	carrefourURL         = "https://online.carrefour.com.tw/CarrefourECProduct/GetSearchJson"
 	carrefourQueryFormat = "pageIndex=%d&pageSize=%d&OrderById=0"
-	cfNum                = 35
+	// the same search of one category
+	carrefourCategoryFormat = "pageIndex=%d&pageSize=%d&OrderById=0&categoryId=%s"
+	cfNum                   = 35
 )
 
 // CfItem carrefour item
@@ -53,19 +57,29 @@ type CfJson struct {
 	Success int `json:"success"`
 }
 
-// Carrefour hold task RT-mart
+// Carrefour hold task Carrefour
 type Carrefour struct {
-	Name     string
-	Context  *app.Context
-	interval int64
+	Name    string
+	Context *app.Context
+	conf    Config
+}
+
+func init() {
+	Register("Carrefour", func(context *app.Context, conf Config) CrawlerTask {
+		return NewCarrefour(context, conf)
+	})
 }
 
 // NewCarrefour new task
-func NewCarrefour(context *app.Context, interval int64) *Carrefour {
+func NewCarrefour(context *app.Context, conf Config) *Carrefour {
 	task := new(Carrefour)
 	task.Name = "Carrefour"
 	task.Context = context
-	task.interval = interval
+	if conf.PageSize <= 0 {
+		conf.PageSize = cfNum
+	}
+	conf.Name = task.Name
+	task.conf = conf
 	return task
 }
 
@@ -74,21 +88,26 @@ func (task *Carrefour) String() string {
 }
 
 // Run main loop
-func (task *Carrefour) Run() {
-
-	for {
-		// FIXME only one go routine here, more advance version to use worker
-		// maybe block from upstream
-		begin := time.Now().Truncate(time.Second)
-		log.Println(task.Name, begin)
-		task.Do()
-		log.Println("Fetch all pages", task.Name, time.Now().Sub(begin))
-		time.Sleep(time.Duration(task.interval) * time.Second)
-	}
+func (task *Carrefour) Run(ctx context.Context) {
+	loop(ctx, task.Context, task.conf, task.Do)
 }
 
 // Do do the dirty job
-func (task *Carrefour) Do() {
+func (task *Carrefour) Do(ctx context.Context, run *schema.CrawlRun) error {
+	cats := categories(task.Context.Categories, task.Name, task.conf.Categories)
+	return eachCategory(ctx, run, cats, task.crawl)
+}
+
+// payload of page in cat, nil cat for the whole listing
+func (task *Carrefour) payload(cat *schema.Category, page int) string {
+	if cat == nil {
+		return fmt.Sprintf(carrefourQueryFormat, page, task.conf.PageSize)
+	}
+	return fmt.Sprintf(carrefourCategoryFormat, page, task.conf.PageSize, cat.Code)
+}
+
+// crawl every page of cat
+func (task *Carrefour) crawl(ctx context.Context, run *schema.CrawlRun, cat *schema.Category) error {
 
 	var totalPage int
 	var err error
@@ -97,55 +116,69 @@ func (task *Carrefour) Do() {
 	var jsresp CfJson
 
 	// first page url
-	payload = fmt.Sprintf(carrefourQueryFormat, 1, cfNum)
+	payload = task.payload(cat, 1)
 	log.Println(payload)
 
-	jsonBytes, err = fetchPostBytes(carrefourURL, payload)
+	jsonBytes, err = fetchPostBytes(ctx, carrefourURL, payload)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
 
 	err = json.Unmarshal(jsonBytes, &jsresp)
 	if err != nil {
-		log.Println(err)
-		return
+		// counted as error by the caller
+		metrics.ParseFailures.WithLabelValues(task.Name).Inc()
+		return err
 	}
 
 	log.Println(jsresp.Success, jsresp.Content.Count, len(jsresp.Content.ProductListModel))
-	if jsresp.Success == 1 {
-		totalPage = jsresp.Content.Count/cfNum + 1
-		task.process(jsresp.Content.ProductListModel)
+	if jsresp.Success != 1 {
+		// counted as error by the caller
+		metrics.ParseFailures.WithLabelValues(task.Name).Inc()
+		return fmt.Errorf("carrefour: first page not success %d", jsresp.Success)
 	}
+	fetched(run)
+	totalPage = jsresp.Content.Count/task.conf.PageSize + 1
+	expect(run, totalPage)
+	task.process(ctx, run, cat, jsresp.Content.ProductListModel)
 
 	for page := 2; page <= totalPage; page++ {
 		// not DDOS the site
-		time.Sleep(3 * time.Second)
-		payload = fmt.Sprintf(carrefourQueryFormat, page, cfNum)
+		if !sleep(ctx, time.Duration(task.conf.Delay)*time.Second) {
+			return ctx.Err()
+		}
+		payload = task.payload(cat, page)
 		log.Println(payload, "of", totalPage)
-		jsonBytes, err = fetchPostBytes(carrefourURL, payload)
+		jsonBytes, err = fetchPostBytes(ctx, carrefourURL, payload)
 		if err != nil {
-			log.Println(err)
+			fail(run, err)
 			continue
 		}
 
 		var jsp CfJson
 		err = json.Unmarshal(jsonBytes, &jsp)
 		if err != nil {
-			log.Println(err)
+			parseFailed(run, err)
 			continue
 		}
-		if jsresp.Success == 1 {
-			task.process(jsp.Content.ProductListModel)
+		if jsp.Success != 1 {
+			parseFailed(run, fmt.Errorf("carrefour: page %d not success %d", page, jsp.Success))
+			continue
 		}
+		fetched(run)
+		task.process(ctx, run, cat, jsp.Content.ProductListModel)
 	}
+	return ctx.Err()
 }
 
-func (task *Carrefour) process(items []CfItem) {
-	// <div class="indexProList">
+func (task *Carrefour) process(ctx context.Context, run *schema.CrawlRun, cat *schema.Category, items []CfItem) {
 	for _, item := range items {
-		var origItem, newItem schema.Item
-		var err error
+		var newItem schema.Item
+
+		// stop before next write, the written ones are complete
+		if ctx.Err() != nil {
+			return
+		}
 		if item.SeName == "" {
 			continue
 		}
@@ -154,55 +187,22 @@ func (task *Carrefour) process(items []CfItem) {
 		newItem.Imgsrc = item.PictureUrl
 		newItem.Name = item.Name
 		newItem.Note = item.Specification
-		if priceInt, err := strconv.Atoi(item.Price); err == nil {
-			newItem.Price = priceInt
+		// not saved without a price, a zero would look like a drop
+		regular, err := strconv.Atoi(item.Price)
+		if err != nil || regular <= 0 {
+			parseFailed(run, fmt.Errorf("carrefour: price %q of %s", item.Price, newItem.Url))
+			continue
 		}
+		// empty or zero special price when no promotion, a promotion badge alone is no discount
+		special, _ := strconv.Atoi(item.SpecialPrice)
+		newItem.SetPrices(regular, special)
+		newItem.PackQty = item.ItemQtyPerPack
 
 		now := time.Now().Truncate(time.Second)
 		newItem.Created = now
 		newItem.Updated = now
 		newItem.Source = task.Name
-		// log.Printf("%#v\n", newItem)
-		// log.Println(origItem)
-		// check exist
-		err = task.Context.DB.Get(&origItem, "SELECT * from item WHERE url = $1 LIMIT 1", newItem.Url)
-
-		switch {
-		case err == sql.ErrNoRows:
-			// no row in that url
-			_, err = task.Context.DB.NamedExec(`INSERT INTO item 
-			(price, diff, name, url, imgsrc, source, note, created, updated ) VALUES (
-			:price,
-			:diff,
-			:name,
-			:url,
-			:imgsrc,
-			:source,
-			:note,
-			:created,
-			:updated)`, newItem)
-			if err != nil {
-				log.Println(err)
-			}
-		case err != nil:
-			log.Println(err)
-		default:
-			// compare existed one
-			newItem.Diff = newItem.Price - origItem.Price
-			_, err = task.Context.DB.NamedExec(`UPDATE item SET
-			price=:price,
-			diff=:diff,
-			name=:name,
-			url=:url,
-			imgsrc=:imgsrc,
-			source=:source,
-			note=:note,
-			updated=:updated WHERE url=:url`, newItem)
-
-			if err != nil {
-				log.Println(err)
-			}
-
-		}
+		inCategory(&newItem, cat)
+		upsert(task.Context.Items, run, &newItem, store.Observation{})
 	}
 }
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+
+	"honestman/app"
+)
+
+// Config for one crawler task, most come from the crawler config file
+type Config struct {
+	Name     string `json:"name" yaml:"name"`
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Interval int64  `json:"interval" yaml:"interval"`   // seconds to sleep between each crawl
+	PageSize int    `json:"page_size" yaml:"page_size"` // items per page, zero for task default
+	Delay    int64  `json:"delay" yaml:"delay"`         // seconds between pages, not DDOS the site
+	Grace    int64  `json:"grace" yaml:"grace"`         // seconds an item may be missing from partial runs before delisted
+
+	// crawl category by category, parent before its children, empty for the whole listing
+	Categories []CategoryConfig `json:"categories" yaml:"categories"`
+}
+
+// CategoryConfig one category of the store site
+type CategoryConfig struct {
+	Code   string `json:"code" yaml:"code"` // category id on the store site
+	Name   string `json:"name" yaml:"name"`
+	Parent string `json:"parent" yaml:"parent"` // code of parent category, empty for root
+}
+
+// Factory build a task from its config
+type Factory func(context *app.Context, conf Config) CrawlerTask
+
+var registry = make(map[string]Factory)
+
+// Register make a task available by name, call it in init of the task file
+func Register(name string, factory Factory) {
+	if _, dup := registry[name]; dup {
+		panic("task: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Names of all registered tasks
+func Names() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New build the registered task by conf.Name
+func New(context *app.Context, conf Config) (CrawlerTask, error) {
+	factory, ok := registry[conf.Name]
+	if !ok {
+		return nil, fmt.Errorf("task: unknown task %q, registered %v", conf.Name, Names())
+	}
+	if conf.Interval <= 0 {
+		// one day second = 24 * 60 * 60 = 86400
+		// 8 * 60 * 60  = 28800
+		conf.Interval = 28800
+	}
+	if conf.Delay <= 0 {
+		conf.Delay = 3
+	}
+	if conf.Grace <= 0 {
+		// 3 days
+		conf.Grace = 259200
+	}
+	return factory(context, conf), nil
+}
+
+// DefaultConfig run every registered task when no config file given
+func DefaultConfig() []Config {
+	var configs []Config
+	for _, name := range Names() {
+		configs = append(configs, Config{Name: name, Enabled: true})
+	}
+	return configs
+}
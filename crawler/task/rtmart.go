@@ -1,14 +1,18 @@
 package task
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"honestman/app"
+	"honestman/metrics"
 	"honestman/schema"
+	"honestman/store"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -17,23 +21,34 @@ var (
 	// should hit their db slowly
 	// onece 100
 	// rturlFormat = "http://www.rt-mart.com.tw/direct/index.php?action=product_search&prod_keyword=&p_data_num=100&page=%d"
-	rturlFormat = "http://www.rt-mart.com.tw/direct/index.php?action=product_search&prod_keyword=&p_data_num=%d&page=%d"
-	rtNum       = 100
+	rturlFormat         = "http://www.rt-mart.com.tw/direct/index.php?action=product_search&prod_keyword=&p_data_num=%d&page=%d"
+	rtCategoryURLFormat = "http://www.rt-mart.com.tw/direct/index.php?action=product_sort&prod_sort_uid=%s&p_data_num=%d&page=%d"
+	rtNum               = 100
 )
 
 // RTmart hold task RT-mart
 type RTmart struct {
-	Name     string
-	Context  *app.Context
-	interval int64
+	Name    string
+	Context *app.Context
+	conf    Config
+}
+
+func init() {
+	Register("RTmart", func(context *app.Context, conf Config) CrawlerTask {
+		return NewRTmart(context, conf)
+	})
 }
 
 // NewRTmart new task
-func NewRTmart(context *app.Context, interval int64) *RTmart {
+func NewRTmart(context *app.Context, conf Config) *RTmart {
 	task := new(RTmart)
 	task.Name = "RTmart"
 	task.Context = context
-	task.interval = interval
+	if conf.PageSize <= 0 {
+		conf.PageSize = rtNum
+	}
+	conf.Name = task.Name
+	task.conf = conf
 	return task
 }
 
@@ -42,21 +57,26 @@ func (task *RTmart) String() string {
 }
 
 // Run main loop
-func (task *RTmart) Run() {
-
-	for {
-		// FIXME only one go routine here, more advance version to use worker
-		// maybe block from upstream
-		begin := time.Now().Truncate(time.Second)
-		log.Println(task.Name, begin)
-		task.Do()
-		log.Println("Fetch all pages", task.Name, time.Now().Sub(begin))
-		time.Sleep(time.Duration(task.interval) * time.Second)
-	}
+func (task *RTmart) Run(ctx context.Context) {
+	loop(ctx, task.Context, task.conf, task.Do)
 }
 
 // Do do the dirty job
-func (task *RTmart) Do() {
+func (task *RTmart) Do(ctx context.Context, run *schema.CrawlRun) error {
+	cats := categories(task.Context.Categories, task.Name, task.conf.Categories)
+	return eachCategory(ctx, run, cats, task.crawl)
+}
+
+// pageURL of page in cat, nil cat for the whole listing
+func (task *RTmart) pageURL(cat *schema.Category, page int) string {
+	if cat == nil {
+		return fmt.Sprintf(rturlFormat, task.conf.PageSize, page)
+	}
+	return fmt.Sprintf(rtCategoryURLFormat, cat.Code, task.conf.PageSize, page)
+}
+
+// crawl every page of cat
+func (task *RTmart) crawl(ctx context.Context, run *schema.CrawlRun, cat *schema.Category) error {
 
 	var totalPage, total int
 	var err error
@@ -64,45 +84,68 @@ func (task *RTmart) Do() {
 	var doc *goquery.Document
 
 	// first page url
-	url = fmt.Sprintf(rturlFormat, rtNum, 1)
+	url = task.pageURL(cat, 1)
 	log.Println(url)
-	doc, err = goquery.NewDocument(url)
+	doc, err = fetchDocument(ctx, url)
 	if err != nil {
-		log.Println(err)
-		return
+		return err
 	}
-	task.process(doc)
 	totalStr := doc.Find("span.t02").Text()
 	log.Println("Found", totalStr)
-	if totalStr != "" {
-		total, err = strconv.Atoi(totalStr)
+	if totalStr == "" {
+		// counted as error by the caller
+		metrics.ParseFailures.WithLabelValues(task.Name).Inc()
+		return fmt.Errorf("rtmart: no item count on %s", url)
+	}
+
+	total, err = strconv.Atoi(totalStr)
+	if err != nil {
+		// counted as error by the caller
+		metrics.ParseFailures.WithLabelValues(task.Name).Inc()
+		return err
+	}
+	// alwayse plus one page
+	totalPage = total/task.conf.PageSize + 1
+	fetched(run)
+	expect(run, totalPage)
+	task.process(ctx, run, cat, doc)
+
+	for page := 2; page <= totalPage; page++ {
+		// not DDOS the site
+		if !sleep(ctx, time.Duration(task.conf.Delay)*time.Second) {
+			return ctx.Err()
+		}
+		url = task.pageURL(cat, page)
+		log.Println(url, "of", totalPage)
+		doc, err = fetchDocument(ctx, url)
 		if err != nil {
-			log.Println(err)
-			return
+			fail(run, err)
+			continue
 		}
-		// alwayse plus one page
-		totalPage = total/rtNum + 1
-
-		for page := 2; page <= totalPage; page++ {
-			// not DDOS the site
-			time.Sleep(3 * time.Second)
-			url = fmt.Sprintf(rturlFormat, rtNum, page)
-			log.Println(url, "of", totalPage)
-			doc, err = goquery.NewDocument(url)
-			task.process(doc)
-			if err != nil {
-				log.Println(err)
-				continue
-			}
+		fetched(run)
+		task.process(ctx, run, cat, doc)
+	}
+	return ctx.Err()
+}
 
-		}
+func fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
+	resp, err := fetchGet(ctx, url)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
+	return goquery.NewDocumentFromReader(resp.Body)
 }
 
-func (task *RTmart) process(doc *goquery.Document) {
+func (task *RTmart) process(ctx context.Context, run *schema.CrawlRun, cat *schema.Category, doc *goquery.Document) {
 	// <div class="indexProList">
 	doc.Find("div.indexProList").Each(func(i int, s *goquery.Selection) {
-		var origItem, newItem schema.Item
+		var newItem schema.Item
+
+		// stop before next write, the written ones are complete
+		if ctx.Err() != nil {
+			return
+		}
 
 		if url, ok := s.Find("h5.for_proname > a").Attr("href"); ok {
 			newItem.Url = url
@@ -116,59 +159,27 @@ func (task *RTmart) process(doc *goquery.Document) {
 		price := s.Find("div.for_pricebox > div").Text()
 
 		if newItem.Url != "" {
-			// should be an Item
-			var err error
-			priceStr := price[1:]
-			if priceInt, err := strconv.Atoi(priceStr); err == nil {
-				newItem.Price = priceInt
+			// should be an Item, not saved without a price, a zero would look like a drop
+			priceInt, err := strconv.Atoi(priceDigits(price))
+			if err != nil || priceInt <= 0 {
+				parseFailed(run, fmt.Errorf("rtmart: price %q of %s", price, newItem.Url))
+				return
 			}
+			newItem.SetPrices(priceInt, 0)
+			newItem.PackQty = 1
 			now := time.Now().Truncate(time.Second)
 			newItem.Created = now
 			newItem.Updated = now
 			newItem.Source = task.Name
+			inCategory(&newItem, cat)
 
-			// check exist
-			err = task.Context.DB.Get(&origItem, "SELECT * from item WHERE url = $1 LIMIT 1", newItem.Url)
-
-			err = task.Context.DB.Get(&origItem, "SELECT * from item WHERE url = $1 LIMIT 1", newItem.Url)
-
-			switch {
-			case err == sql.ErrNoRows:
-				// no row in that url
-				_, err = task.Context.DB.NamedExec(`INSERT INTO item 
-				(price, diff, name, url, imgsrc, source, note, created, updated ) VALUES (
-				:price,
-				:diff,
-				:name,
-				:url,
-				:imgsrc,
-				:source,
-				:note,
-				:created,
-				:updated)`, newItem)
-				if err != nil {
-					log.Println(err)
-				}
-			case err != nil:
-				log.Println(err)
-			default:
-				// compare existed one
-				newItem.Diff = newItem.Price - origItem.Price
-				_, err = task.Context.DB.NamedExec(`UPDATE item SET
-				price=:price,
-				diff=:diff,
-				name=:name,
-				url=:url,
-				imgsrc=:imgsrc,
-				source=:source,
-				note=:note,
-				updated=:updated WHERE url=:url`, newItem)
-
-				if err != nil {
-					log.Println(err)
-				}
-
-			}
+			upsert(task.Context.Items, run, &newItem, store.Observation{})
 		}
 	})
 }
+
+// priceDigits "$1,299" as "1299"
+func priceDigits(price string) string {
+	price = strings.TrimLeftFunc(price, func(r rune) bool { return !unicode.IsDigit(r) })
+	return strings.Replace(strings.TrimSpace(price), ",", "", -1)
+}
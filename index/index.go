@@ -0,0 +1,405 @@
+// Package index embedded inverted index of items, full text search without PostgreSQL.
+// Words come from item.tokens, items are kept in a gob snapshot file,
+// written by the crawler and reloaded by the api when changed.
+package index
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"honestman/match"
+	"honestman/schema"
+	"honestman/store"
+)
+
+// ErrCategoryTree category id with sub categories need the database
+var ErrCategoryTree = errors.New("index: search by category id is not supported, use category path")
+
+// reloadEvery at most, stat the snapshot for changes
+const reloadEvery = 10 * time.Second
+
+// Index items by word
+type Index struct {
+	path string
+
+	mu       sync.RWMutex
+	docs     map[int]schema.Item
+	postings map[string]map[int]bool    // word to item ids
+	grams    map[string]map[string]bool // runes and rune pairs to the words having them
+	dirty    bool                       // changed since saved
+	modTime  time.Time                  // of snapshot loaded or saved
+	checked  time.Time                  // last stat of snapshot
+}
+
+// Open index of snapshot path, empty when the file not exist yet
+func Open(path string) (*Index, error) {
+	idx := &Index{path: path}
+	idx.reset(nil)
+	if err := idx.Reload(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// reset docs and postings to items, caller hold the lock
+func (idx *Index) reset(items []schema.Item) {
+	idx.docs = make(map[int]schema.Item)
+	idx.postings = make(map[string]map[int]bool)
+	idx.grams = make(map[string]map[string]bool)
+	for _, item := range items {
+		idx.add(item)
+	}
+}
+
+// add item to docs and postings, caller hold the lock
+func (idx *Index) add(item schema.Item) {
+	idx.docs[item.Id] = item
+	for _, word := range strings.Fields(item.Tokens) {
+		ids, ok := idx.postings[word]
+		if !ok {
+			ids = make(map[int]bool)
+			idx.postings[word] = ids
+			for _, g := range grams(word) {
+				if idx.grams[g] == nil {
+					idx.grams[g] = make(map[string]bool)
+				}
+				idx.grams[g][word] = true
+			}
+		}
+		ids[item.Id] = true
+	}
+}
+
+// remove item of id from docs and postings, caller hold the lock
+func (idx *Index) remove(id int) {
+	orig, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, word := range strings.Fields(orig.Tokens) {
+		delete(idx.postings[word], id)
+		if len(idx.postings[word]) == 0 {
+			delete(idx.postings, word)
+			for _, g := range grams(word) {
+				delete(idx.grams[g], word)
+				if len(idx.grams[g]) == 0 {
+					delete(idx.grams, g)
+				}
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Put index item, replace the one of the same id
+func (idx *Index) Put(item schema.Item) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	item.Score = 0
+	idx.remove(item.Id)
+	idx.add(item)
+	idx.dirty = true
+}
+
+// Len items in index
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Save snapshot when changed, written to a temp file then renamed
+func (idx *Index) Save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty {
+		return nil
+	}
+
+	items := make([]schema.Item, 0, len(idx.docs))
+	for _, item := range idx.docs {
+		items = append(items, item)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(idx.path), filepath.Base(idx.path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = gob.NewEncoder(tmp).Encode(items); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), idx.path); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(idx.path); err == nil {
+		idx.modTime = info.ModTime()
+	}
+	idx.dirty = false
+	return nil
+}
+
+// Run save the snapshot every interval and when ctx done
+func (idx *Index) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := idx.Save(); err != nil {
+				log.Println(err)
+			}
+			return
+		case <-ticker.C:
+			if err := idx.Save(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// Reload snapshot when the file changed since loaded or saved
+func (idx *Index) Reload() error {
+	info, err := os.Stat(idx.path)
+	if err != nil {
+		return err
+	}
+
+	idx.mu.RLock()
+	unchanged := info.ModTime().Equal(idx.modTime)
+	idx.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var items []schema.Item
+	if err = gob.NewDecoder(f).Decode(&items); err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.reset(items)
+	idx.modTime = info.ModTime()
+	idx.dirty = false
+	return nil
+}
+
+// refresh reload the snapshot at most every reloadEvery, for the reading process
+func (idx *Index) refresh() {
+	idx.mu.Lock()
+	due := time.Since(idx.checked) >= reloadEvery && !idx.dirty
+	if due {
+		idx.checked = time.Now()
+	}
+	idx.mu.Unlock()
+
+	if due {
+		if err := idx.Reload(); err != nil && !os.IsNotExist(err) {
+			log.Println(err)
+		}
+	}
+}
+
+// Search items as the database does, keywords looked up by word
+func (idx *Index) Search(q store.SearchQuery) ([]schema.Item, int, error) {
+	found, err := idx.matches(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	return store.Rank(found, q), len(found), nil
+}
+
+// Facets of every hit of q, most hits first
+func (idx *Index) Facets(q store.SearchQuery) (*schema.Facets, error) {
+	found, err := idx.matches(q)
+	if err != nil {
+		return nil, err
+	}
+	return store.CountFacets(found), nil
+}
+
+// Suggest names and brands completing text, brand guessed from name as products are matched
+func (idx *Index) Suggest(text string, limit int) ([]schema.Suggestion, error) {
+	idx.refresh()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	items := make([]schema.Item, 0, len(idx.docs))
+	for _, id := range idx.candidates(store.SearchQuery{}) {
+		items = append(items, idx.docs[id])
+	}
+	brand := func(item schema.Item) string {
+		return match.Brand(item.Name)
+	}
+	return store.SuggestFrom(items, brand, text, limit), nil
+}
+
+// matches every item of q
+func (idx *Index) matches(q store.SearchQuery) ([]schema.Item, error) {
+	if q.Category > 0 {
+		return nil, ErrCategoryTree
+	}
+	idx.refresh()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var found []schema.Item
+	for _, id := range idx.candidates(q) {
+		if item := idx.docs[id]; q.Matches(item, nil) {
+			found = append(found, item)
+		}
+	}
+	return found, nil
+}
+
+// candidates ids may match keywords of q, every item without keyword, caller hold the lock
+func (idx *Index) candidates(q store.SearchQuery) []int {
+	var set map[int]bool
+
+	// intersect keeps ids in both, the first one as is
+	intersect := func(ids map[int]bool) {
+		if set == nil {
+			set = ids
+			return
+		}
+		for id := range set {
+			if !ids[id] {
+				delete(set, id)
+			}
+		}
+	}
+
+	for _, kw := range q.Keywords {
+		intersect(idx.lookup(kw))
+	}
+	for _, group := range q.AnyOf {
+		alts := make(map[int]bool)
+		for _, kw := range group {
+			for id := range idx.lookup(kw) {
+				alts[id] = true
+			}
+		}
+		intersect(alts)
+	}
+
+	var ids []int
+	if set == nil {
+		for id := range idx.docs {
+			ids = append(ids, id)
+		}
+	} else {
+		for id := range set {
+			ids = append(ids, id)
+		}
+	}
+	// stable order of equal ranks, as by id in the database
+	sort.Ints(ids)
+	return ids
+}
+
+// lookup ids of items having every word of kw next to each other, inside a longer word
+// also counts as ILIKE does, caller hold the lock
+func (idx *Index) lookup(kw string) map[int]bool {
+	kw = strings.ToLower(kw)
+	parts := strings.Fields(kw)
+
+	var set map[int]bool
+	for _, part := range parts {
+		ids := make(map[int]bool)
+		for _, word := range idx.words(part) {
+			for id := range idx.postings[word] {
+				ids[id] = true
+			}
+		}
+		if set != nil {
+			for id := range set {
+				if !ids[id] {
+					delete(set, id)
+				}
+			}
+		} else {
+			set = ids
+		}
+	}
+	if set == nil {
+		set = make(map[int]bool)
+	}
+
+	// a phrase, the words should be adjacent as well
+	if len(parts) > 1 {
+		for id := range set {
+			if !strings.Contains(idx.docs[id].Tokens, kw) {
+				delete(set, id)
+			}
+		}
+	}
+	return set
+}
+
+// words of the index containing part, by the rarest rune pair of part, caller hold the lock
+func (idx *Index) words(part string) []string {
+	r := []rune(part)
+	var rarest map[string]bool
+	if len(r) == 1 {
+		rarest = idx.grams[part]
+	}
+	for i := 0; i+1 < len(r); i++ {
+		words := idx.grams[string(r[i:i+2])]
+		if len(words) == 0 {
+			return nil
+		}
+		if rarest == nil || len(words) < len(rarest) {
+			rarest = words
+		}
+	}
+
+	var found []string
+	for word := range rarest {
+		if strings.Contains(word, part) {
+			found = append(found, word)
+		}
+	}
+	return found
+}
+
+// grams of word, every rune and every pair of adjacent runes
+func grams(word string) []string {
+	r := []rune(word)
+	var result []string
+	for i := range r {
+		result = append(result, string(r[i]))
+		if i+1 < len(r) {
+			result = append(result, string(r[i:i+2]))
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"honestman/schema"
+	"honestman/segment"
+)
+
+func newIndex(t *testing.T, names ...string) *Index {
+	idx, err := Open(filepath.Join(os.TempDir(), "honestman-index-test-not-exist.gob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range names {
+		idx.Put(schema.Item{Id: i + 1, Name: name, Tokens: segment.Tokens(name)})
+	}
+	return idx
+}
+
+func sorted(set map[int]bool) string {
+	var ids []int
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+func TestLookup(t *testing.T) {
+	idx := newIndex(t, "光泉 鮮乳 1857ml", "鮮乳 光泉 936ml", "林鳳營 鮮乳", "桂格燕麥片")
+
+	tests := []struct {
+		kw   string
+		want string
+	}{
+		{"鮮乳", "[1 2 3]"},
+		{"乳", "[1 2 3]"},
+		{"ml", "[1 2]"},
+		// inside a longer word
+		{"麥片", "[4]"},
+		// a phrase only where the words are adjacent
+		{"光泉 鮮乳", "[1]"},
+		{"鮮乳 光泉", "[2]"},
+		{"豆漿", "[]"},
+		{"", "[]"},
+	}
+	for _, tt := range tests {
+		if got := sorted(idx.lookup(tt.kw)); got != tt.want {
+			t.Errorf("lookup(%q) = %s, want %s", tt.kw, got, tt.want)
+		}
+	}
+}
+
+func TestLookupReplaced(t *testing.T) {
+	idx := newIndex(t, "桂格燕麥片")
+	idx.Put(schema.Item{Id: 1, Name: "蜂蜜檸檬", Tokens: segment.Tokens("蜂蜜檸檬")})
+
+	if got := sorted(idx.lookup("燕麥")); got != "[]" {
+		t.Errorf("lookup of replaced name = %s, want []", got)
+	}
+	if got := sorted(idx.lookup("檸檬")); got != "[1]" {
+		t.Errorf("lookup of new name = %s, want [1]", got)
+	}
+	if words := idx.grams["燕麥"]; len(words) != 0 {
+		t.Errorf("grams of replaced name %v, want none", words)
+	}
+}
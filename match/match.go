@@ -0,0 +1,131 @@
+// Package match group items of different retailers into products,
+// by similar name, quantity and brand, the stores give no barcode.
+package match
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"honestman/schema"
+	"honestman/store"
+	"honestman/unit"
+)
+
+// batch unmatched items read at once
+const batch = 500
+
+// Matcher put unmatched items into products
+type Matcher struct {
+	Products  store.ProductRepository
+	MinScore  float64 // minimum trigram similarity of names
+	Tolerance float64 // quantity difference allowed, 0.05 is 5%
+}
+
+// New matcher with default thresholds
+func New(products store.ProductRepository) *Matcher {
+	return &Matcher{Products: products, MinScore: 0.5, Tolerance: 0.05}
+}
+
+// Run match every interval until ctx done
+func (m *Matcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := m.MatchAll(ctx)
+		if err != nil {
+			log.Println("match", err)
+		}
+		if n > 0 {
+			log.Println("Matched", n, "items")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// MatchAll match every unmatched item, return how many matched
+func (m *Matcher) MatchAll(ctx context.Context) (int, error) {
+	count := 0
+	for ctx.Err() == nil {
+		items, err := m.Products.Unmatched(batch)
+		if err != nil || len(items) == 0 {
+			return count, err
+		}
+		for _, item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			if _, err := m.Match(item); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Match put item into the product of the same goods from another retailer,
+// or into a new product of its own, return the product id
+func (m *Matcher) Match(item schema.Item) (int, error) {
+	brand := Brand(item.Name)
+
+	// without quantity never merge, "鮮奶" of 290ml and 1857ml are not the same
+	if item.Quantity > 0 {
+		candidates, err := m.Products.Similar(item, Name(item.Name), m.MinScore, 10)
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range candidates {
+			if !m.sameQuantity(item.Quantity, c.Quantity) {
+				continue
+			}
+			if other := Brand(c.Name); brand != "" && other != "" && other != brand {
+				continue
+			}
+			return *c.ProductId, m.Products.SetProduct(item.Id, *c.ProductId)
+		}
+	}
+
+	p := schema.Product{
+		Name:     item.Name,
+		Brand:    brand,
+		Quantity: item.Quantity,
+		Unit:     item.Unit,
+	}
+	if err := m.Products.CreateProduct(&p); err != nil {
+		return 0, err
+	}
+	return p.Id, m.Products.SetProduct(item.Id, p.Id)
+}
+
+func (m *Matcher) sameQuantity(a, b float64) bool {
+	return math.Abs(a-b) <= m.Tolerance*math.Max(a, b)
+}
+
+// Name normalized for comparison, lower case without quantity
+func Name(name string) string {
+	return unit.Strip(name)
+}
+
+// Brand the first word of name when there are more words, empty when unsure
+func Brand(name string) string {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return ""
+	}
+	for _, r := range words[0] {
+		if unicode.IsDigit(r) {
+			return ""
+		}
+	}
+	return strings.ToLower(words[0])
+}
@@ -0,0 +1,67 @@
+package match
+
+import (
+	"testing"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+func TestBrand(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"光泉 鮮乳 1857ml", "光泉"},
+		{"鮮乳", ""},
+		// a leading number is no brand
+		{"3M 膠帶", ""},
+	}
+	for _, tt := range tests {
+		if got := Brand(tt.name); got != tt.want {
+			t.Errorf("Brand(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	mem := store.NewMemory()
+	m := New(mem)
+
+	// quantity as the crawler sets it
+	item := func(source, name string, quantity float64) schema.Item {
+		item := schema.Item{Source: source, Url: source + "/" + name, Name: name, Price: 100, Quantity: quantity}
+		if quantity > 0 {
+			item.Unit = "ml"
+		}
+		if _, err := mem.Upsert(&item, store.Observation{}); err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+
+	first := item("RTmart", "光泉 嚴選全脂鮮乳 1857ml", 1857)
+	tests := []struct {
+		name string
+		item schema.Item
+		same bool
+	}{
+		{"same goods of another store", item("Carrefour", "光泉 嚴選全脂鮮乳 1857ML", 1857), true},
+		{"other quantity", item("Carrefour", "光泉 嚴選全脂鮮乳 290ml", 290), false},
+		{"other brand", item("Carrefour", "林鳳營 嚴選全脂鮮乳 1857ml", 1857), false},
+		{"no quantity", item("Carrefour", "光泉 嚴選全脂鮮乳", 0), false},
+	}
+
+	product, err := m.Match(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		got, err := m.Match(tt.item)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if (got == product) != tt.same {
+			t.Errorf("%s: product %d, first %d, want same %v", tt.name, got, product, tt.same)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// RequestDuration api latency by route pattern
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "honestman_api_request_duration_seconds",
+		Help:    "API request latency by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	// SearchResults how many items a search found
+	SearchResults = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "honestman_api_search_results",
+		Help:    "Number of items matched by /api/search.",
+		Buckets: []float64{0, 1, 10, 50, 100, 500, 1000, 5000},
+	})
+
+	// PagesFetched listing pages fetched by source
+	PagesFetched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "honestman_crawler_pages_fetched_total",
+		Help: "Listing pages fetched from retailer.",
+	}, []string{"source"})
+
+	// RetailerResponses http status code from retailer by host
+	RetailerResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "honestman_crawler_http_responses_total",
+		Help: "HTTP responses from retailer by host and status code, code is error when no response.",
+	}, []string{"host", "code"})
+
+	// ParseFailures page or item could not be parsed
+	ParseFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "honestman_crawler_parse_failures_total",
+		Help: "Pages or items which could not be parsed.",
+	}, []string{"source"})
+
+	// ItemsUpserted items saved by source and result (inserted, updated, unchanged, delisted, error)
+	ItemsUpserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "honestman_crawler_items_upserted_total",
+		Help: "Items saved by source and result.",
+	}, []string{"source", "result"})
+
+	// LastSuccess unix time of the last successful crawl by source
+	LastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "honestman_crawler_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful crawl.",
+	}, []string{"source"})
+
+	// WebhookDropped webhook events dropped by type, both the queue and the overflow were full
+	WebhookDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "honestman_webhook_events_dropped_total",
+		Help: "Webhook events dropped because the dispatcher was full.",
+	}, []string{"event"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		RequestDuration,
+		SearchResults,
+		PagesFetched,
+		RetailerResponses,
+		ParseFailures,
+		ItemsUpserted,
+		LastSuccess,
+		WebhookDropped,
+	)
+}
+
+// Handler serve /metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// statusWriter remember the status code
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Route middleware observe latency of route, use the pattern not the path
+// to keep label cardinality low
+func Route(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			begin := time.Now()
+			sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			RequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(sw.code)).Observe(time.Since(begin).Seconds())
+		})
+	}
+}
+
+// Transport count retailer status codes of every request through next
+type Transport struct {
+	Next http.RoundTripper
+}
+
+// RoundTrip implement http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		RetailerResponses.WithLabelValues(req.URL.Host, "error").Inc()
+		return resp, err
+	}
+	RetailerResponses.WithLabelValues(req.URL.Host, strconv.Itoa(resp.StatusCode)).Inc()
+	return resp, nil
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE price_history
+(
+    id            serial primary key,
+    item_id       integer references item(id) on delete cascade,
+    price         integer default 0,
+    special_price integer default 0,
+    observed_at   timestamp default NOW(),
+    crawl_run_id  integer
+);
+
+CREATE INDEX price_history_item_observed ON price_history ( item_id, observed_at );
+
+-- backfill, the last known price of every item is the first observation
+INSERT INTO price_history (item_id, price, special_price, observed_at)
+SELECT id, price, 0, coalesce(updated, created) FROM item;
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE price_history;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+
+-- keep the first row of every (source, url), move history of duplicates to it
+UPDATE price_history h SET item_id = d.keep FROM (
+    SELECT id, min(id) OVER (PARTITION BY source, url) AS keep FROM item
+) d WHERE h.item_id = d.id AND d.id <> d.keep;
+
+DELETE FROM item a USING item b
+WHERE a.source = b.source AND a.url = b.url AND a.id > b.id;
+
+ALTER TABLE item ADD CONSTRAINT item_source_url UNIQUE (source, url);
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE item DROP CONSTRAINT item_source_url;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE crawl_run
+(
+    id          serial primary key,
+    task        text default '',
+    status      text default 'running',
+    started     timestamp default NOW(),
+    finished    timestamp,
+    pages       integer default 0,
+    inserted    integer default 0,
+    updated     integer default 0,
+    unchanged   integer default 0,
+    errors      integer default 0,
+    last_error  text default ''
+);
+
+CREATE INDEX crawl_run_task_started ON crawl_run ( task, started );
+
+ALTER TABLE price_history ADD CONSTRAINT price_history_crawl_run
+    FOREIGN KEY (crawl_run_id) REFERENCES crawl_run(id) ON DELETE SET NULL;
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE price_history DROP CONSTRAINT price_history_crawl_run;
+DROP TABLE crawl_run;
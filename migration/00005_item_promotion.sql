@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE item ADD COLUMN regular_price integer default 0;
+ALTER TABLE item ADD COLUMN promo_price integer default 0;
+ALTER TABLE item ADD COLUMN on_sale boolean default false;
+ALTER TABLE item ADD COLUMN pack_qty integer default 1;
+
+UPDATE item SET regular_price = price;
+
+CREATE INDEX item_on_sale ON item ( on_sale ) WHERE on_sale;
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX item_on_sale;
+ALTER TABLE item DROP COLUMN pack_qty;
+ALTER TABLE item DROP COLUMN on_sale;
+ALTER TABLE item DROP COLUMN promo_price;
+ALTER TABLE item DROP COLUMN regular_price;
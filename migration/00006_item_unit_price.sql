@@ -0,0 +1,16 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- filled by the crawler on the next crawl
+ALTER TABLE item ADD COLUMN quantity double precision default 0;
+ALTER TABLE item ADD COLUMN unit text default '';
+ALTER TABLE item ADD COLUMN unit_price double precision default 0;
+
+CREATE INDEX item_unit_price ON item ( unit, unit_price );
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX item_unit_price;
+ALTER TABLE item DROP COLUMN unit_price;
+ALTER TABLE item DROP COLUMN unit;
+ALTER TABLE item DROP COLUMN quantity;
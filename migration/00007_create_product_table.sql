@@ -0,0 +1,21 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE product
+(
+    id        serial primary key,
+    name      text default '',
+    brand     text default '',
+    quantity  double precision default 0,
+    unit      text default '',
+    created   timestamp default NOW()
+);
+
+ALTER TABLE item ADD COLUMN product_id integer references product(id) on delete set null;
+
+CREATE INDEX item_product ON item ( product_id );
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE item DROP COLUMN product_id;
+DROP TABLE product;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE watch
+(
+    id             serial primary key,
+    item_id        integer references item(id) on delete cascade,
+    query          text default '',
+    target_price   integer default 0,
+    channel        text default 'log',
+    address        text default '',
+    created        timestamp default NOW(),
+    last_notified  timestamp,
+    -- sha256 hex of the token given once to whom created the watch
+    token_hash     text NOT NULL
+);
+
+CREATE INDEX watch_item ON watch ( item_id );
+CREATE INDEX watch_target_price ON watch ( target_price );
+CREATE UNIQUE INDEX watch_token_hash ON watch ( token_hash );
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE watch;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE webhook_dead_letter
+(
+    id          serial primary key,
+    url         text default '',
+    event       text default '',
+    payload     text default '',
+    attempts    integer default 0,
+    last_error  text default '',
+    created     timestamp default NOW()
+);
+
+CREATE INDEX webhook_dead_letter_created ON webhook_dead_letter ( created );
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE webhook_dead_letter;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE item ADD COLUMN available boolean default true;
+ALTER TABLE item ADD COLUMN last_seen timestamp;
+
+UPDATE item SET last_seen = coalesce(updated, created);
+
+CREATE INDEX item_source_last_seen ON item ( source, last_seen ) WHERE available;
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX item_source_last_seen;
+ALTER TABLE item DROP COLUMN last_seen;
+ALTER TABLE item DROP COLUMN available;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE category
+(
+    id          serial primary key,
+    source      text default '',
+    code        text default '',
+    name        text default '',
+    parent_id   integer references category(id) on delete set null,
+    path        text default '',
+    created     timestamp default NOW(),
+    unique (source, code)
+);
+
+CREATE INDEX category_parent ON category ( parent_id );
+
+ALTER TABLE item ADD COLUMN category_id integer references category(id) on delete set null;
+
+CREATE INDEX item_category ON item ( category_id );
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE item DROP COLUMN category_id;
+DROP TABLE category;
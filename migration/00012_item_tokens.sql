@@ -0,0 +1,14 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- segmented by the crawler on next upsert, lower name until then
+ALTER TABLE item ADD COLUMN tokens text default '';
+
+UPDATE item SET tokens = lower(name);
+
+CREATE INDEX item_tokens ON item USING gin(tokens gin_trgm_ops);
+
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX item_tokens;
+ALTER TABLE item DROP COLUMN tokens;
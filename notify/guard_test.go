@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"honestman/schema"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		// ipv4 mapped in ipv6
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("PublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWebhook(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"http://127.0.0.1:8080/hook", false},
+		{"https://10.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://localhost/hook", false},
+		{"ftp://8.8.8.8/hook", false},
+		{"8.8.8.8/hook", false},
+		{"https://8.8.8.8/hook", true},
+	}
+	for _, tt := range tests {
+		if err := CheckWebhook(tt.url); (err == nil) != tt.ok {
+			t.Errorf("CheckWebhook(%s) = %v, want ok %v", tt.url, err, tt.ok)
+		}
+	}
+}
+
+func TestWebhookSinkIgnoresProxy(t *testing.T) {
+	// a proxy would be dialed instead of the webhook host
+	var proxied int
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+	}))
+	defer proxy.Close()
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Setenv(name, proxy.URL)
+	}
+
+	sink := NewWebhookSink(time.Second)
+	if sink.Client.Transport.(*http.Transport).Proxy != nil {
+		t.Fatal("webhook transport uses a proxy")
+	}
+
+	for _, address := range []string{"http://10.0.0.1/hook", "https://192.168.1.1/hook", "http://169.254.169.254/latest"} {
+		err := sink.Send(Notification{Watch: schema.Watch{Address: address}})
+		if !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("Send to %s = %v, want %v", address, err, ErrPrivateAddress)
+		}
+	}
+	if proxied > 0 {
+		t.Errorf("%d requests through the proxy", proxied)
+	}
+}
@@ -0,0 +1,90 @@
+// Package notify tell watchers when an item drop to their target price.
+// Delivery is pluggable by Sink, one for each watch channel.
+package notify
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+// Notification one item satisfies one watch
+type Notification struct {
+	Watch schema.Watch `json:"watch"`
+	Item  schema.Item  `json:"item"`
+}
+
+// Sink deliver notification
+type Sink interface {
+	Send(n Notification) error
+}
+
+// Notifier queue dropped items, evaluate watches and send by sink of watch channel
+type Notifier struct {
+	Watches store.WatchRepository
+	Sinks   map[string]Sink // by watch channel
+	queue   chan schema.Item
+}
+
+// New notifier with a queue of size
+func New(watches store.WatchRepository, sinks map[string]Sink, size int) *Notifier {
+	return &Notifier{
+		Watches: watches,
+		Sinks:   sinks,
+		queue:   make(chan schema.Item, size),
+	}
+}
+
+// PriceDropped enqueue item, never block the crawler, drop when the queue is full
+func (n *Notifier) PriceDropped(item schema.Item) {
+	select {
+	case n.queue <- item:
+	default:
+		log.Println("notify: queue full, drop", item.Id, item.Name)
+	}
+}
+
+// Run deliver queued items until ctx done, the queued ones are drained first
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case item := <-n.queue:
+			n.deliver(item)
+		case <-ctx.Done():
+			for {
+				select {
+				case item := <-n.queue:
+					n.deliver(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (n *Notifier) deliver(item schema.Item) {
+	watches, err := n.Watches.MatchWatches(item)
+	if err != nil {
+		log.Println("notify:", err)
+		return
+	}
+
+	for _, w := range watches {
+		sink, ok := n.Sinks[w.Channel]
+		if !ok {
+			log.Println("notify: no sink for channel", w.Channel, "of watch", w.Id)
+			continue
+		}
+		if err := sink.Send(Notification{Watch: w, Item: item}); err != nil {
+			log.Println("notify: watch", w.Id, err)
+			continue
+		}
+		if err := n.Watches.Notified(w.Id, time.Now().Truncate(time.Second)); err != nil {
+			log.Println("notify:", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// LogSink write notification to log, for test and local run
+type LogSink struct{}
+
+// Send implement Sink
+func (LogSink) Send(n Notification) error {
+	log.Printf("notify: watch %d %s now %d (target %d) %s", n.Watch.Id, n.Item.Name, n.Item.Price, n.Watch.TargetPrice, n.Item.Url)
+	return nil
+}
+
+// WebhookSink POST notification as json to watch address
+type WebhookSink struct {
+	Client *http.Client
+}
+
+// NewWebhookSink sink with timeout, only public hosts are reached
+func NewWebhookSink(timeout time.Duration) *WebhookSink {
+	// no proxy, the dialer should see the webhook host and not the proxy
+	transport := &http.Transport{
+		Proxy:       nil,
+		DialContext: publicDialer(timeout),
+	}
+	return &WebhookSink{Client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// Send implement Sink
+func (s *WebhookSink) Send(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Post(n.Watch.Address, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", n.Watch.Address, resp.Status)
+	}
+	return nil
+}
+
+// SMTPConfig mail server
+type SMTPConfig struct {
+	Addr     string `json:"addr" yaml:"addr"` // host:port
+	From     string `json:"from" yaml:"from"`
+	Username string `json:"username" yaml:"username"` // empty for no auth
+	Password string `json:"password" yaml:"password"`
+}
+
+// SMTPSink mail notification to watch address
+type SMTPSink struct {
+	Config SMTPConfig
+}
+
+// Send implement Sink
+func (s *SMTPSink) Send(n Notification) error {
+	var auth smtp.Auth
+	if s.Config.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Config.Addr)
+		auth = smtp.PlainAuth("", s.Config.Username, s.Config.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.Config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.Watch.Address)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fmt.Sprintf("Honestman %s $%d", n.Item.Name, n.Item.Price)))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", n.Item.Name)
+	fmt.Fprintf(&msg, "%s $%d (%+d), target $%d\r\n", n.Item.Source, n.Item.Price, n.Item.Diff, n.Watch.TargetPrice)
+	fmt.Fprintf(&msg, "%s\r\n", n.Item.Url)
+
+	return smtp.SendMail(s.Config.Addr, auth, s.Config.From, []string{n.Watch.Address}, []byte(msg.String()))
+}
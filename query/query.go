@@ -0,0 +1,272 @@
+// Package query parse the search language of /api/search into store.SearchQuery.
+//
+//	蜂蜜 檸檬              both keywords
+//	"蜂蜜 檸檬"            the phrase
+//	-果糖                 not the keyword
+//	蜂蜜 OR 糖漿           either keyword, | is the same
+//	source:RTmart         only items of store
+//	price:<100            also <=, >, >=, =, 50..100
+//	category:水果          category path contains, or category id
+//	unit:g sale:1 sort:price_desc
+//
+// Keywords are normalized and segmented as item names are indexed,
+// "蜂蜜檸檬" is the same as 蜂蜜 檸檬, a phrase or OR keyword stays one.
+// Item names are matched unsegmented as well, 麥片 finds "桂格燕麥片" split as 燕麥 片.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"honestman/segment"
+	"honestman/store"
+	"honestman/unit"
+)
+
+// fields known, other "name:value" is a keyword
+var fields = map[string]bool{
+	"source":   true,
+	"price":    true,
+	"category": true,
+	"unit":     true,
+	"sale":     true,
+	"sort":     true,
+}
+
+// token one term of query
+type token struct {
+	field  string // empty for keyword
+	value  string
+	not    bool // leading -
+	or     bool // OR operator
+	quoted bool
+}
+
+// Parse s into q, filters of s override what q already has
+func Parse(s string, q *store.SearchQuery) error {
+	var terms [][]token
+	var or bool
+
+	for _, tok := range tokenize(segment.Fold(s)) {
+		if tok.or {
+			if len(terms) == 0 || or {
+				return fmt.Errorf("query: OR should be between keywords")
+			}
+			or = true
+			continue
+		}
+
+		if tok.field == "" && !tok.not {
+			if tok.value == "" {
+				continue
+			}
+			if or {
+				last := len(terms) - 1
+				terms[last] = append(terms[last], tok)
+			} else {
+				terms = append(terms, []token{tok})
+			}
+			or = false
+			continue
+		}
+
+		if or {
+			return fmt.Errorf("query: OR should be between keywords")
+		}
+		switch {
+		case tok.field != "" && tok.not:
+			return fmt.Errorf("query: -%s: can not exclude a filter", tok.field)
+		case tok.field != "":
+			if err := filter(tok.field, tok.value, q); err != nil {
+				return err
+			}
+		default:
+			// nothing left of punctuation would exclude every item
+			if kw := segment.Index(tok.value); kw != "" {
+				q.Excludes = append(q.Excludes, kw)
+			}
+		}
+	}
+	if or {
+		return fmt.Errorf("query: OR should be between keywords")
+	}
+
+	for _, term := range terms {
+		switch {
+		case len(term) > 1:
+			var group []string
+			for _, tok := range term {
+				if kw := segment.Index(tok.value); kw != "" {
+					group = append(group, kw)
+				}
+			}
+			// an empty member would match every item
+			if len(group) < 2 {
+				return fmt.Errorf("query: OR should be between keywords")
+			}
+			q.AnyOf = append(q.AnyOf, group)
+		case term[0].quoted:
+			if kw := segment.Index(term[0].value); kw != "" {
+				q.Keywords = append(q.Keywords, kw)
+			}
+		default:
+			q.Keywords = append(q.Keywords, segment.Words(term[0].value)...)
+		}
+	}
+	return nil
+}
+
+// tokenize split s by space, keep quoted phrase as one
+func tokenize(s string) []token {
+	var tokens []token
+
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		if unicode.IsSpace(r[i]) {
+			i++
+			continue
+		}
+
+		var tok token
+		if r[i] == '-' && i+1 < len(r) && !unicode.IsSpace(r[i+1]) {
+			tok.not = true
+			i++
+		}
+
+		// field name before colon
+		start := i
+		for i < len(r) && !unicode.IsSpace(r[i]) && r[i] != ':' && r[i] != '"' {
+			i++
+		}
+		if name := strings.ToLower(string(r[start:i])); i < len(r) && r[i] == ':' && fields[name] {
+			tok.field = name
+			i++
+		} else {
+			i = start
+		}
+
+		tok.quoted = i < len(r) && r[i] == '"'
+		if tok.quoted {
+			i++
+			start = i
+			for i < len(r) && r[i] != '"' {
+				i++
+			}
+			tok.value = string(r[start:i])
+			if i < len(r) {
+				// closing quote
+				i++
+			}
+		} else {
+			start = i
+			for i < len(r) && !unicode.IsSpace(r[i]) {
+				i++
+			}
+			tok.value = string(r[start:i])
+		}
+
+		if !tok.quoted && !tok.not && tok.field == "" && (tok.value == "OR" || tok.value == "|") {
+			tok.or = true
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// filter apply field:value on q
+func filter(field, value string, q *store.SearchQuery) error {
+	switch field {
+	case "source":
+		q.Source = value
+	case "price":
+		return price(value, q)
+	case "category":
+		if id, err := strconv.Atoi(value); err == nil {
+			q.Category = id
+		} else {
+			q.CategoryPath = value
+		}
+	case "unit":
+		switch value {
+		case unit.Gram, unit.Milli, unit.Piece:
+			q.Unit = value
+		default:
+			return fmt.Errorf("query: unit:%s, should be g, ml or pc", value)
+		}
+	case "sale":
+		q.OnSale = value == "1" || value == "true" || value == "yes"
+	case "sort":
+		for _, sort := range store.Sorts {
+			if value == sort {
+				q.Sort = value
+				return nil
+			}
+		}
+		return fmt.Errorf("query: sort:%s, should be one of %s", value, strings.Join(store.Sorts, ", "))
+	}
+	return nil
+}
+
+// price range of "<100", "<=100", ">50", ">=50", "=80", "80" or "50..100",
+// a bound given replaces the one q has, the range should not be empty
+func price(value string, q *store.SearchQuery) error {
+	atoi := func(s string) (*int, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("query: price:%s, invalid price", value)
+		}
+		return &n, nil
+	}
+
+	var min, max *int
+	var err error
+	switch {
+	case strings.Contains(value, ".."):
+		bounds := strings.SplitN(value, "..", 2)
+		if bounds[0] == "" && bounds[1] == "" {
+			return fmt.Errorf("query: price:%s, invalid price", value)
+		}
+		if bounds[0] != "" {
+			if min, err = atoi(bounds[0]); err != nil {
+				return err
+			}
+		}
+		if bounds[1] != "" {
+			max, err = atoi(bounds[1])
+		}
+	case strings.HasPrefix(value, "<="):
+		max, err = atoi(value[2:])
+	case strings.HasPrefix(value, "<"):
+		if max, err = atoi(value[1:]); err == nil {
+			if *max == 0 {
+				return fmt.Errorf("query: price:%s, no price is below 0", value)
+			}
+			*max--
+		}
+	case strings.HasPrefix(value, ">="):
+		min, err = atoi(value[2:])
+	case strings.HasPrefix(value, ">"):
+		if min, err = atoi(value[1:]); err == nil {
+			*min++
+		}
+	default:
+		min, err = atoi(strings.TrimPrefix(value, "="))
+		max = min
+	}
+	if err != nil {
+		return err
+	}
+
+	if min != nil {
+		q.MinPrice = min
+	}
+	if max != nil {
+		q.MaxPrice = max
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return fmt.Errorf("query: price:%s, lowest %d is above highest %d", value, *q.MinPrice, *q.MaxPrice)
+	}
+	return nil
+}
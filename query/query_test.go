@@ -0,0 +1,78 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"honestman/store"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want store.SearchQuery
+	}{
+		{`蜂蜜檸檬`, store.SearchQuery{Keywords: []string{"蜂蜜", "檸檬"}}},
+		{`"蜂蜜 檸檬" -果糖`, store.SearchQuery{Keywords: []string{"蜂蜜 檸檬"}, Excludes: []string{"果糖"}}},
+		{`牛奶 OR 豆漿`, store.SearchQuery{AnyOf: [][]string{{"牛奶", "豆漿"}}}},
+		{`牛奶 | 豆漿 蜂蜜`, store.SearchQuery{Keywords: []string{"蜂蜜"}, AnyOf: [][]string{{"牛奶", "豆漿"}}}},
+		{`鮮奶 OR "!!" OR 豆漿`, store.SearchQuery{AnyOf: [][]string{{"鮮奶", "豆漿"}}}},
+		{`ＡＢＣ 鲜奶`, store.SearchQuery{Keywords: []string{"abc", "鮮奶"}}},
+		{`source:RTmart category:水果 unit:ml sale:1 sort:price_desc`, store.SearchQuery{
+			Source: "RTmart", CategoryPath: "水果", Unit: "ml", OnSale: true, Sort: "price_desc",
+		}},
+		{`category:3`, store.SearchQuery{Category: 3}},
+		// unknown field is a keyword
+		{`foo:bar`, store.SearchQuery{Keywords: []string{"foo", "bar"}}},
+		{`price:<100`, store.SearchQuery{MaxPrice: intp(99)}},
+		{`price:<=100`, store.SearchQuery{MaxPrice: intp(100)}},
+		{`price:>50`, store.SearchQuery{MinPrice: intp(51)}},
+		{`price:>=50`, store.SearchQuery{MinPrice: intp(50)}},
+		{`price:50..100`, store.SearchQuery{MinPrice: intp(50), MaxPrice: intp(100)}},
+		{`price:..100`, store.SearchQuery{MaxPrice: intp(100)}},
+		{`price:80`, store.SearchQuery{MinPrice: intp(80), MaxPrice: intp(80)}},
+		// zero is a bound, not none
+		{`price:<1`, store.SearchQuery{MaxPrice: intp(0)}},
+		{`price:0`, store.SearchQuery{MinPrice: intp(0), MaxPrice: intp(0)}},
+	}
+	for _, tt := range tests {
+		var got store.SearchQuery
+		if err := Parse(tt.in, &got); err != nil {
+			t.Errorf("Parse(%q) error %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []string{
+		`OR 牛奶`,
+		`牛奶 OR`,
+		`牛奶 OR OR 豆漿`,
+		`牛奶 OR -豆漿`,
+		`鮮奶 OR "!!"`,
+		`"!!" | "??"`,
+		`-source:RTmart`,
+		`unit:kg`,
+		`sort:name`,
+		`price:abc`,
+		`price:-1`,
+		`price:<0`,
+		`price:..`,
+		`price:100..50`,
+		`price:>10 price:<5`,
+	}
+	for _, in := range tests {
+		var q store.SearchQuery
+		if err := Parse(in, &q); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", in, q)
+		}
+	}
+}
+
+func intp(n int) *int {
+	return &n
+}
@@ -1,19 +1,218 @@
 package schema
 
 import (
+	"strings"
 	"time"
+
+	"honestman/segment"
 )
 
 type Item struct {
+	Id           int       `db:"id" json:"id"`
+	Price        int       `db:"price" json:"price"` // Only for New Taiwan Dollars, no cents, what to pay now
+	Diff         int       `db:"diff" json:"diff"`
+	RegularPrice int       `db:"regular_price" json:"regular_price"`
+	PromoPrice   int       `db:"promo_price" json:"promo_price"` // zero when not on sale
+	OnSale       bool      `db:"on_sale" json:"on_sale"`
+	PackQty      int       `db:"pack_qty" json:"pack_qty"`
+	Quantity     float64   `db:"quantity" json:"quantity"`     // in unit, zero when unknown
+	Unit         string    `db:"unit" json:"unit"`             // g, ml or pc
+	UnitPrice    float64   `db:"unit_price" json:"unit_price"` // per 100g, per litre or per piece
+	Name         string    `db:"name" json:"name"`
+	Tokens       string    `db:"tokens" json:"-"`          // segmented normalized name and the name unsegmented, matched by search
+	Category     string    `db:"category" json:"category"` // path of category, "生鮮 > 水果"
+	CategoryId   *int      `db:"category_id" json:"category_id,omitempty"`
+	Url          string    `db:"url" json:"url"`
+	Imgsrc       string    `db:"imgsrc" json:"imgsrc"`
+	Source       string    `db:"source" json:"source"`
+	Note         string    `db:"note" json:"note"`
+	ProductId    *int      `db:"product_id" json:"product_id,omitempty"`
+	Available    bool      `db:"available" json:"available"` // false when not seen in recent crawls
+	LastSeen     time.Time `db:"last_seen" json:"last_seen"`
+	Created      time.Time `db:"created" json:"created"`
+	Updated      time.Time `db:"updated" json:"updated,omitempty"`
+	Score        float64   `db:"score" json:"score,omitempty"` // relevance to search keywords, only in search
+}
+
+// SetPrices set regular and promotion price, on sale only when the promotion is cheaper,
+// price is the promotion one then
+func (item *Item) SetPrices(regular, promo int) {
+	item.RegularPrice = regular
+	item.Price = regular
+	item.PromoPrice = 0
+	item.OnSale = false
+	if promo > 0 && promo < regular {
+		item.PromoPrice = promo
+		item.Price = promo
+		item.OnSale = true
+	}
+}
+
+// Category of a store, a tree by ParentId
+type Category struct {
 	Id       int       `db:"id" json:"id"`
-	Price    int       `db:"price" json:"price"` // Only for New Taiwan Dollars, no cents
-	Diff     int       `db:"diff" json:"diff"`
-	Name     string    `db:"name" json:"name"`
-	Category string    `db:"category" json:"category"`
-	Url      string    `db:"url" json:"url"`
-	Imgsrc   string    `db:"imgsrc" json:"imgsrc"`
 	Source   string    `db:"source" json:"source"`
-	Note     string    `db:"note" json:"note"`
+	Code     string    `db:"code" json:"code"` // category id on the store site
+	Name     string    `db:"name" json:"name"`
+	ParentId *int      `db:"parent_id" json:"parent_id,omitempty"`
+	Path     string    `db:"path" json:"path"`   // names from the root, "生鮮 > 水果"
+	Items    int       `db:"items" json:"items"` // available items, sub categories included
 	Created  time.Time `db:"created" json:"-"`
-	Updated  time.Time `db:"updated" json:"updated,omitempty"`
+}
+
+// Source a store crawled, items counted
+type Source struct {
+	Name        string     `db:"name" json:"name"`
+	Items       int        `db:"items" json:"items"`
+	Available   int        `db:"available" json:"available"`
+	Updated     time.Time  `db:"updated" json:"updated"`          // the latest item update
+	LastSuccess *time.Time `db:"-" json:"last_success,omitempty"` // finished of the last successful crawl
+}
+
+// Product the same goods sold by different retailers, each item is an offer
+type Product struct {
+	Id       int       `db:"id" json:"id"`
+	Name     string    `db:"name" json:"name"`
+	Brand    string    `db:"brand" json:"brand"`
+	Quantity float64   `db:"quantity" json:"quantity"`
+	Unit     string    `db:"unit" json:"unit"`
+	Created  time.Time `db:"created" json:"created"`
+}
+
+// Facets hits of a search counted by field, for filter sidebars
+type Facets struct {
+	Source   []FacetCount `json:"source"`
+	Category []FacetCount `json:"category"` // by category path
+	Price    []FacetCount `json:"price"`    // by price bucket, value as "50..99" of price filter
+	OnSale   int          `json:"on_sale"`
+}
+
+// FacetCount hits of one value
+type FacetCount struct {
+	Value string `db:"value" json:"value"`
+	Count int    `db:"count" json:"count"`
+}
+
+// Suggestion completion of what user is typing
+type Suggestion struct {
+	Text  string  `db:"text" json:"text"`
+	Kind  string  `db:"kind" json:"kind"`   // "name" or "brand"
+	Count int     `db:"count" json:"count"` // available items of the name or the brand
+	Score float64 `db:"score" json:"score"`
+}
+
+// PriceHistory one observation of item price, append only
+type PriceHistory struct {
+	Id           int       `db:"id" json:"id"`
+	ItemId       int       `db:"item_id" json:"item_id"`
+	Price        int       `db:"price" json:"price"`
+	SpecialPrice int       `db:"special_price" json:"special_price"`
+	ObservedAt   time.Time `db:"observed_at" json:"observed_at"`
+	CrawlRunId   *int      `db:"crawl_run_id" json:"crawl_run_id,omitempty"`
+}
+
+// PriceChange price of an item differ from the observation before
+type PriceChange struct {
+	Price    int       `db:"price" json:"price"`
+	Previous int       `db:"previous" json:"previous"`
+	Diff     int       `db:"diff" json:"diff"` // price - previous, negative for a drop
+	Changed  time.Time `db:"changed" json:"changed"`
+}
+
+// Mover item whose price moved in a window, from the price at the window start
+type Mover struct {
+	Item
+	From    int     `db:"from_price" json:"from"`
+	Change  int     `db:"change" json:"change"`   // price - from, negative for a drop
+	Percent float64 `db:"percent" json:"percent"` // change of from in percent, one decimal
+}
+
+// PricePoint aggregated price of an item in one period
+type PricePoint struct {
+	Period time.Time `db:"period" json:"period"`
+	Min    int       `db:"min" json:"min"`
+	Max    int       `db:"max" json:"max"`
+	Avg    float64   `db:"avg" json:"avg"`
+	Last   int       `db:"last" json:"last"`
+	Count  int       `db:"count" json:"count"`
+}
+
+// crawl run status
+const (
+	RunRunning     = "running"
+	RunSuccess     = "success"
+	RunFailed      = "failed"
+	RunInterrupted = "interrupted"
+)
+
+// CrawlRun bookkeeping of one crawl of a task
+type CrawlRun struct {
+	Id         int        `db:"id" json:"id"`
+	Task       string     `db:"task" json:"task"`
+	Status     string     `db:"status" json:"status"`
+	Started    time.Time  `db:"started" json:"started"`
+	Finished   *time.Time `db:"finished" json:"finished,omitempty"`
+	Pages      int        `db:"pages" json:"pages"`
+	Inserted   int        `db:"inserted" json:"inserted"`
+	Updated    int        `db:"updated" json:"updated"`
+	Unchanged  int        `db:"unchanged" json:"unchanged"`
+	Errors     int        `db:"errors" json:"errors"`
+	LastError  string     `db:"last_error" json:"last_error,omitempty"`
+	Expected   int        `db:"-" json:"-"` // pages to fetch, known from the first page of each listing
+	Categories []int      `db:"-" json:"-"` // category ids crawled, nil for the whole listing, only their items are delisted
+}
+
+// watch delivery channel
+const (
+	ChannelWebhook = "webhook"
+	ChannelSMTP    = "smtp"
+	ChannelLog     = "log"
+)
+
+// Watch subscribe an item or a search query, notify when price drop to target
+type Watch struct {
+	Id           int        `db:"id" json:"id"`
+	ItemId       *int       `db:"item_id" json:"item_id,omitempty"`
+	Query        string     `db:"query" json:"query,omitempty"`
+	TargetPrice  int        `db:"target_price" json:"target_price"`
+	Channel      string     `db:"channel" json:"channel"`
+	Address      string     `db:"address" json:"address,omitempty"` // webhook url or email, not shown once created
+	Token        string     `db:"-" json:"token,omitempty"`         // shown only when created, for reading and deleting
+	TokenHash    string     `db:"token_hash" json:"-"`              // sha256 hex of token
+	Created      time.Time  `db:"created" json:"created"`
+	LastNotified *time.Time `db:"last_notified" json:"last_notified,omitempty"`
+}
+
+// Matches item at or below target price, the watched item or name has every query keyword,
+// keywords and name are segmented and normalized as search does
+func (w *Watch) Matches(item Item) bool {
+	// no price is not a bargain
+	if item.Price <= 0 || item.Price > w.TargetPrice {
+		return false
+	}
+	if w.ItemId != nil {
+		return *w.ItemId == item.Id
+	}
+	tokens := item.Tokens
+	if tokens == "" {
+		tokens = segment.Tokens(item.Name)
+	}
+	keywords := segment.Words(w.Query)
+	for _, kw := range keywords {
+		if !strings.Contains(tokens, kw) {
+			return false
+		}
+	}
+	return len(keywords) > 0
+}
+
+// DeadLetter webhook event which could not be delivered after every retry
+type DeadLetter struct {
+	Id        int       `db:"id" json:"id"`
+	Url       string    `db:"url" json:"url"`
+	Event     string    `db:"event" json:"event"`
+	Payload   string    `db:"payload" json:"payload"`
+	Attempts  int       `db:"attempts" json:"attempts"`
+	LastError string    `db:"last_error" json:"last_error"`
+	Created   time.Time `db:"created" json:"created"`
 }
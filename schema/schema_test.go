@@ -0,0 +1,54 @@
+package schema
+
+import "testing"
+
+func TestSetPrices(t *testing.T) {
+	tests := []struct {
+		regular, promo int
+		price          int
+		onSale         bool
+	}{
+		{100, 0, 100, false},
+		{100, 80, 80, true},
+		// special as high as regular is no sale
+		{100, 100, 100, false},
+		{100, 120, 100, false},
+		{0, 80, 0, false},
+	}
+	for _, tt := range tests {
+		var item Item
+		item.SetPrices(tt.regular, tt.promo)
+		if item.Price != tt.price || item.OnSale != tt.onSale {
+			t.Errorf("SetPrices(%d, %d) price %d on sale %v, want %d %v",
+				tt.regular, tt.promo, item.Price, item.OnSale, tt.price, tt.onSale)
+		}
+	}
+}
+
+func TestWatchMatches(t *testing.T) {
+	id := 1
+	tests := []struct {
+		name  string
+		watch Watch
+		item  Item
+		want  bool
+	}{
+		{"item at target", Watch{ItemId: &id, TargetPrice: 100}, Item{Id: 1, Price: 100}, true},
+		{"item above target", Watch{ItemId: &id, TargetPrice: 100}, Item{Id: 1, Price: 101}, false},
+		{"other item", Watch{ItemId: &id, TargetPrice: 100}, Item{Id: 2, Price: 50}, false},
+		{"query", Watch{Query: "蜂蜜 檸檬", TargetPrice: 100}, Item{Name: "蜂蜜檸檬汁", Price: 50}, true},
+		{"query missing keyword", Watch{Query: "蜂蜜 檸檬", TargetPrice: 100}, Item{Name: "蜂蜜", Price: 50}, false},
+		{"upper case query", Watch{Query: "COCA", TargetPrice: 100}, Item{Name: "Coca Cola 可口可樂 600ml", Price: 29}, true},
+		{"full width query", Watch{Query: "ｃｏｃａ 可樂", TargetPrice: 100}, Item{Name: "Coca Cola 可口可樂 600ml", Price: 29}, true},
+		{"full width name", Watch{Query: "coca", TargetPrice: 100}, Item{Name: "ＣＯＣＡ ＣＯＬＡ", Price: 29}, true},
+		{"simplified query", Watch{Query: "鲜奶", TargetPrice: 100}, Item{Name: "光泉鮮奶", Price: 50}, true},
+		{"tokens of store", Watch{Query: "麥片", TargetPrice: 100}, Item{Name: "桂格燕麥片", Tokens: "桂格 燕麥 片 桂格燕麥片", Price: 50}, true},
+		{"punctuation only", Watch{Query: "!!", TargetPrice: 100}, Item{Name: "蜂蜜", Price: 50}, false},
+		{"no price", Watch{ItemId: &id, TargetPrice: 100}, Item{Id: 1, Price: 0}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.watch.Matches(tt.item); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
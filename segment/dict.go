@@ -0,0 +1,19 @@
+package segment
+
+// dictionary words of grocery in Traditional Chinese, longer match win
+const dictionary = `
+蜂蜜 檸檬 柳橙 柳丁 葡萄 葡萄柚 蘋果 香蕉 鳳梨 芒果 奇異果 草莓 藍莓 蔓越莓 西瓜 木瓜 水蜜桃 水梨 芭樂 百香果 柚子 椰子 火龍果
+番茄 小番茄 高麗菜 花椰菜 青江菜 菠菜 地瓜 馬鈴薯 紅蘿蔔 白蘿蔔 洋蔥 青蔥 大蒜 老薑 玉米 南瓜 香菇 金針菇 杏鮑菇 木耳 豆芽 蘆筍 秋葵 茄子 辣椒
+豬肉 牛肉 雞肉 羊肉 鴨肉 雞蛋 鴨蛋 雞胸 雞腿 雞翅 排骨 五花肉 絞肉 培根 火腿 香腸 熱狗 貢丸 魚丸 肉鬆 肉乾
+鮭魚 鯛魚 鱈魚 虱目魚 鮪魚 秋刀魚 蝦仁 白蝦 草蝦 花枝 魷魚 蛤蜊 牡蠣 干貝 螃蟹 海帶 海苔 紫菜
+牛奶 鮮奶 鮮乳 保久乳 奶粉 羊奶 豆漿 米漿 優格 優酪乳 乳酪 起司 奶油 鮮奶油 煉乳 奶精 布丁 果凍 冰淇淋 雪糕
+白米 糙米 糯米 五穀 燕麥 麥片 穀片 麵粉 麵條 麵包 吐司 饅頭 包子 水餃 餛飩 湯圓 年糕 蘿蔔糕 冬粉 米粉 義大利麵 泡麵 拉麵 烏龍麵 意麵
+餅乾 蘇打餅 夾心餅 洋芋片 薯條 爆米花 巧克力 糖果 口香糖 軟糖 喉糖 堅果 杏仁 腰果 核桃 花生 開心果 葵瓜子 蜜餞 果乾 海苔片 蛋捲 鳳梨酥 牛軋糖
+醬油 醬油膏 烏醋 白醋 米酒 味醂 味噌 沙茶 豆瓣醬 辣椒醬 番茄醬 沙拉醬 美乃滋 芥末 胡椒 胡椒粉 咖哩 鹽巴 食鹽 砂糖 黑糖 冰糖 果糖 蜂蜜水 香油 麻油 苦茶油 橄欖油 沙拉油 葵花油 芥花油 豬油
+礦泉水 氣泡水 汽水 可樂 果汁 柳橙汁 蘋果汁 檸檬汁 綠茶 紅茶 烏龍茶 奶茶 麥茶 花茶 咖啡 即溶咖啡 咖啡豆 濾掛 運動飲料 啤酒 紅酒 白酒 威士忌 高粱 清酒 梅酒
+衛生紙 面紙 廚房紙巾 濕紙巾 紙巾 抽取式 捲筒 洗衣精 洗衣粉 洗衣球 柔軟精 漂白水 洗碗精 清潔劑 浴廁 芳香劑 除濕 垃圾袋 保鮮膜 鋁箔紙 夾鏈袋
+洗髮精 潤髮乳 護髮 沐浴乳 沐浴露 香皂 洗手乳 洗面乳 卸妝 化妝水 乳液 面膜 防曬 牙膏 牙刷 牙線 漱口水 刮鬍刀 衛生棉 護墊 棉條
+尿布 紙尿褲 奶瓶 嬰兒 幼兒 兒童 成人 寵物 狗糧 貓糧 飼料 貓砂 罐頭 零食
+電池 燈泡 延長線 充電 保溫瓶 保鮮盒 便當盒 杯子 碗盤 筷子 湯匙 平底鍋 炒鍋 電鍋
+有機 無糖 低糖 微糖 減糖 無鹽 低脂 全脂 脫脂 高鈣 高纖 無添加 特濃 原味 經典 家庭號 量販 大容量 超值 組合 禮盒 進口 國產 台灣 日本 韓國 美國 澳洲 紐西蘭 義大利 法國 冷凍 冷藏 常溫 即食 調理包
+`
@@ -0,0 +1,19 @@
+package segment
+
+// traditional of common simplified characters in product names,
+// ambiguous ones valid in both (面, 干, 台, 谷) are left alone
+var traditional = map[rune]rune{
+	'鸡': '雞', '鸭': '鴨', '鱼': '魚', '虾': '蝦', '猪': '豬', '鲜': '鮮', '鲑': '鮭', '鳕': '鱈', '贝': '貝', '蚝': '蠔',
+	'饼': '餅', '饺': '餃', '馄': '餛', '饨': '飩', '饮': '飲', '饲': '飼', '汤': '湯', '酱': '醬', '盐': '鹽', '咸': '鹹',
+	'麦': '麥', '浆': '漿', '芦': '蘆', '笋': '筍', '萝': '蘿', '卜': '蔔', '葱': '蔥', '姜': '薑', '苹': '蘋', '柠': '檸',
+	'蓝': '藍', '红': '紅', '绿': '綠', '黄': '黃', '纸': '紙', '卫': '衛', '发': '髮', '护': '護', '肤': '膚', '剂': '劑',
+	'洁': '潔', '净': '淨', '厨': '廚', '锅': '鍋', '盘': '盤', '装': '裝', '组': '組', '个': '個', '进': '進', '国': '國',
+	'产': '產', '湾': '灣', '韩': '韓', '欧': '歐', '冻': '凍', '热': '熱', '卤': '滷', '软': '軟', '机': '機', '电': '電',
+	'灯': '燈', '视': '視', '线': '線', '头': '頭', '猫': '貓', '宠': '寵', '婴': '嬰', '儿': '兒', '湿': '濕', '温': '溫',
+	'维': '維', '钙': '鈣', '铁': '鐵', '锌': '鋅', '营': '營', '养': '養', '补': '補', '类': '類', '麸': '麩', '苏': '蘇',
+	'矿': '礦', '气': '氣', '无': '無', '减': '減', '轻': '輕', '纤': '纖', '优': '優', '块': '塊', '丝': '絲', '颗': '顆',
+	'双': '雙', '对': '對', '号': '號', '长': '長', '价': '價', '钱': '錢', '选': '選', '礼': '禮', '圆': '圓', '点': '點',
+	'调': '調', '浓': '濃', '缩': '縮', '鲁': '魯', '兰': '蘭', '叶': '葉', '丽': '麗', '乐': '樂', '爱': '愛', '东': '東',
+	'华': '華', '条': '條', '腊': '臘', '肠': '腸', '铝': '鋁', '链': '鏈', '须': '鬚', '龙': '龍', '凤': '鳳', '烧': '燒',
+	'种': '種', '际': '際', '区': '區', '饭': '飯', '团': '團', '鲔': '鮪', '蛎': '蠣',
+}
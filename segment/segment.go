@@ -0,0 +1,121 @@
+// Package segment split Traditional Chinese product names into words,
+// "蜂蜜檸檬汁" to "蜂蜜 檸檬 汁", by forward maximum matching on a bundled dictionary.
+// Text is normalized first: full-width to half-width, simplified to traditional, lower case.
+package segment
+
+import (
+	"strings"
+	"unicode"
+)
+
+var (
+	// words of dictionary
+	words = make(map[string]bool)
+	// maxLen runes of the longest word
+	maxLen = 1
+)
+
+func init() {
+	for _, w := range strings.Fields(dictionary) {
+		r := []rune(w)
+		if len(r) > maxLen {
+			maxLen = len(r)
+		}
+		words[w] = true
+	}
+}
+
+// Fold full-width ASCII to half-width, ideographic space to space
+func Fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xfee0
+		}
+		return r
+	}, s)
+}
+
+// Normalize fold width, simplified to traditional and lower case
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if t, ok := traditional[r]; ok {
+			return t
+		}
+		return unicode.ToLower(r)
+	}, Fold(s))
+}
+
+// Words of normalized s, Han by dictionary, unknown Han runs kept as one word,
+// letters and digits as one word, others split
+func Words(s string) []string {
+	var result []string
+	var unknown []rune
+
+	flush := func() {
+		if len(unknown) > 0 {
+			result = append(result, string(unknown))
+			unknown = unknown[:0]
+		}
+	}
+
+	r := []rune(Normalize(s))
+	for i := 0; i < len(r); {
+		switch {
+		case unicode.Is(unicode.Han, r[i]):
+			n := match(r[i:])
+			if n == 0 {
+				unknown = append(unknown, r[i])
+				i++
+				continue
+			}
+			flush()
+			result = append(result, string(r[i:i+n]))
+			i += n
+		case unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]):
+			flush()
+			start := i
+			for i < len(r) && !unicode.Is(unicode.Han, r[i]) && (unicode.IsLetter(r[i]) || unicode.IsDigit(r[i]) || r[i] == '.') {
+				i++
+			}
+			result = append(result, string(r[start:i]))
+		default:
+			flush()
+			i++
+		}
+	}
+	flush()
+	return result
+}
+
+// Index words of s joined by space, kept in item.tokens and matched by search
+func Index(s string) string {
+	return strings.Join(Words(s), " ")
+}
+
+// Tokens of an item name, Index followed by the normalized name without spaces,
+// "桂格燕麥片" to "桂格 燕麥 片 桂格燕麥片", so a keyword across word boundaries as 麥片 still matches
+func Tokens(name string) string {
+	index := Index(name)
+	whole := strings.Join(strings.Fields(Normalize(name)), "")
+	if whole == "" || whole == index {
+		return index
+	}
+	return index + " " + whole
+}
+
+// match runes of the longest dictionary word at the start of r, zero for none
+func match(r []rune) int {
+	n := maxLen
+	if n > len(r) {
+		n = len(r)
+	}
+	for ; n > 1; n-- {
+		if words[string(r[:n])] {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package segment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"蜂蜜檸檬汁", []string{"蜂蜜", "檸檬汁"}},
+		{"桂格燕麥片", []string{"桂格", "燕麥", "片"}},
+		{"鲜奶 ＡＢＣ", []string{"鮮奶", "abc"}},
+		{"光泉鮮乳 1857ml", []string{"光泉", "鮮乳", "1857ml"}},
+		{"（無糖）綠茶", []string{"無糖", "綠茶"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ＡＢＣ１２３", "abc123"},
+		{"鲜奶　牛奶", "鮮奶 牛奶"},
+		{"Coca-Cola", "coca-cola"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"桂格燕麥片", "桂格 燕麥 片 桂格燕麥片"},
+		{"光泉鮮乳 1857ml", "光泉 鮮乳 1857ml 光泉鮮乳1857ml"},
+		// one word, nothing to add
+		{"蘋果", "蘋果"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Tokens(tt.in); got != tt.want {
+			t.Errorf("Tokens(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"honestman/schema"
+)
+
+// ErrCursor cursor could not be decoded or is of another sort
+var ErrCursor = errors.New("store: invalid cursor")
+
+// Cursor keyset position in search results, the sort keys of one item,
+// stable while items are written unlike OFFSET
+type Cursor struct {
+	Sort      string    `json:"s"`
+	Before    bool      `json:"b,omitempty"` // items before the position, otherwise after
+	Id        int       `json:"i"`
+	Price     int       `json:"p"`
+	UnitPrice float64   `json:"u,omitempty"`
+	Updated   time.Time `json:"t,omitempty"`
+	Diff      int       `json:"d,omitempty"`
+	Score     float64   `json:"r,omitempty"`
+}
+
+// CursorOf position of item in results of sort
+func CursorOf(item schema.Item, sort string, before bool) Cursor {
+	return Cursor{
+		Sort:      sort,
+		Before:    before,
+		Id:        item.Id,
+		Price:     item.Price,
+		UnitPrice: item.UnitPrice,
+		Updated:   item.Updated,
+		Diff:      item.Diff,
+		Score:     item.Score,
+	}
+}
+
+// String token of cursor for url
+func (c Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor token of sort
+func ParseCursor(token, sort string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrCursor
+	}
+	c := new(Cursor)
+	if err = json.Unmarshal(data, c); err != nil || c.Sort != sort {
+		return nil, ErrCursor
+	}
+	return c, nil
+}
+
+// item with sort keys of cursor, to compare with
+func (c Cursor) item() schema.Item {
+	return schema.Item{Id: c.Id, Price: c.Price, UnitPrice: c.UnitPrice, Updated: c.Updated, Diff: c.Diff, Score: c.Score}
+}
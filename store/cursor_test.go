@@ -0,0 +1,143 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"honestman/schema"
+)
+
+// keysLess order of keys on items as Postgres would, the cursor values compared
+func keysLess(keys []sortKey, a, b schema.Item) bool {
+	ca, cb := CursorOf(a, "", false), CursorOf(b, "", false)
+	for _, k := range keys {
+		va, vb := k.value(&ca), k.value(&cb)
+		if va == vb {
+			continue
+		}
+		var lt bool
+		switch va := va.(type) {
+		case int:
+			lt = va < vb.(int)
+		case float64:
+			lt = va < vb.(float64)
+		case string:
+			lt = va < vb.(string)
+		case bool:
+			// false first
+			lt = !va
+		default:
+			panic(fmt.Sprintf("sort key %s of %T", k.expr, va))
+		}
+		return lt != k.desc
+	}
+	return false
+}
+
+func TestLessSortKeys(t *testing.T) {
+	now := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	items := []schema.Item{
+		{Id: 1, Price: 100, UnitPrice: 20, Updated: now, Diff: -10, Score: 0.5},
+		{Id: 2, Price: 100, UnitPrice: 0, Updated: now.Add(time.Hour), Diff: 0, Score: 0.5},
+		{Id: 3, Price: 50, UnitPrice: 20, Updated: now, Diff: -10, Score: 0.9},
+		{Id: 4, Price: 80, UnitPrice: 0, Updated: now.Add(-time.Hour), Diff: 5, Score: 0.1},
+		{Id: 5, Price: 50, UnitPrice: 10, Updated: now.Add(time.Hour), Diff: -20, Score: 0.9},
+		{Id: 6, Price: 120, UnitPrice: 10, Updated: now.Add(1500 * time.Millisecond), Diff: 0, Score: 0},
+	}
+
+	for _, s := range Sorts {
+		byLess := append([]schema.Item(nil), items...)
+		sort.Slice(byLess, func(i, j int) bool { return less(s, byLess[i], byLess[j]) })
+		byKeys := append([]schema.Item(nil), items...)
+		sort.Slice(byKeys, func(i, j int) bool { return keysLess(keysOf(s), byKeys[i], byKeys[j]) })
+
+		for idx := range byLess {
+			if byLess[idx].Id != byKeys[idx].Id {
+				t.Errorf("sort %s: less %v, sortKeys %v", s, ids(byLess), ids(byKeys))
+				break
+			}
+		}
+	}
+}
+
+func TestSeek(t *testing.T) {
+	items := []schema.Item{{Id: 1, Price: 10}, {Id: 2, Price: 10}, {Id: 3, Price: 20}, {Id: 4, Price: 30}, {Id: 5, Price: 30}}
+
+	tests := []struct {
+		pivot  schema.Item
+		before bool
+		want   []int
+	}{
+		{items[1], false, []int{3, 4}},
+		{items[3], true, []int{2, 3}},
+		{items[0], true, nil},
+		{items[4], false, nil},
+	}
+	for _, tt := range tests {
+		c := CursorOf(tt.pivot, "price", tt.before)
+		got := ids(seek(items, "price", &c, 2))
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("seek %d before %v = %v, want %v", tt.pivot.Id, tt.before, got, tt.want)
+		}
+	}
+}
+
+func ids(items []schema.Item) []int {
+	var result []int
+	for _, item := range items {
+		result = append(result, item.Id)
+	}
+	return result
+}
+
+func TestCursorScore(t *testing.T) {
+	// scores of postgres are real widened to float8, the cursor should give back the same
+	for _, score := range []float32{1.0 / 3, 0.1, 0.7777778, 0} {
+		c := CursorOf(schema.Item{Id: 1, Score: float64(score)}, "relevance", false)
+		parsed, err := ParseCursor(c.String(), "relevance")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Score != float64(score) {
+			t.Errorf("score %v back as %v", float64(score), parsed.Score)
+		}
+	}
+}
+
+func TestRelevancePaging(t *testing.T) {
+	mem := NewMemory()
+	// every name as similar to the keyword, ordered by price then id
+	for i, price := range []int{30, 10, 20, 10, 30} {
+		item := schema.Item{Source: "RTmart", Url: fmt.Sprint(i), Name: fmt.Sprintf("蘋果 %c", 'a'+i), Price: price}
+		if _, err := mem.Upsert(&item, Observation{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := SearchQuery{Keywords: []string{"蘋果"}, Sort: "relevance", Page: 1, PerPage: 2}
+	var got []int
+	for pages := 0; pages < 5; pages++ {
+		items, _, err := mem.Search(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) == 0 {
+			break
+		}
+		if items[0].Score != items[len(items)-1].Score {
+			t.Fatalf("scores %v and %v, want ties", items[0].Score, items[len(items)-1].Score)
+		}
+		got = append(got, ids(items)...)
+
+		c, err := ParseCursor(CursorOf(items[len(items)-1], q.Sort, false).String(), q.Sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Cursor = c
+	}
+	if want := []int{2, 4, 3, 1, 5}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("paged %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"honestman/schema"
+)
+
+// Matches item satisfy q, for searches outside the database,
+// categories are q.Category and its descendants, nil for every category
+func (q SearchQuery) Matches(item schema.Item, categories map[int]bool) bool {
+	match := (!q.OnSale || item.OnSale) && (q.Unit == "" || item.Unit == q.Unit) &&
+		(q.Available == nil || item.Available == *q.Available) &&
+		(q.Source == "" || item.Source == q.Source) &&
+		(q.MinPrice == nil || item.Price >= *q.MinPrice) && (q.MaxPrice == nil || item.Price <= *q.MaxPrice) &&
+		(categories == nil || item.CategoryId != nil && categories[*item.CategoryId]) &&
+		(q.CategoryPath == "" || containsFold(item.Category, q.CategoryPath)) &&
+		containsAll(item.Tokens, q.Keywords) && !containsAny(item.Tokens, q.Excludes)
+	for _, group := range q.AnyOf {
+		match = match && containsAny(item.Tokens, group)
+	}
+	return match
+}
+
+// Rank score found items by trigram similarity, no full text outside the database,
+// sort them by q.Sort and return the page of q
+func Rank(found []schema.Item, q SearchQuery) []schema.Item {
+	if text := q.Text(); text != "" {
+		for idx := range found {
+			found[idx].Score = similarity(found[idx].Tokens, text)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return less(q.Sort, found[i], found[j]) })
+	if q.Cursor != nil {
+		return seek(found, q.Sort, q.Cursor, q.PerPage)
+	}
+	return page(found, q.Page, q.PerPage)
+}
+
+// seek perPage sorted items next to cursor
+func seek(sorted []schema.Item, sort string, c *Cursor, perPage int) []schema.Item {
+	pivot := c.item()
+	if c.Before {
+		end := 0
+		for end < len(sorted) && less(sort, sorted[end], pivot) {
+			end++
+		}
+		start := end - perPage
+		if start < 0 {
+			start = 0
+		}
+		return sorted[start:end]
+	}
+
+	start := 0
+	for start < len(sorted) && !less(sort, pivot, sorted[start]) {
+		start++
+	}
+	end := start + perPage
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	return sorted[start:end]
+}
+
+// CountFacets of found items, for searches outside the database
+func CountFacets(found []schema.Item) *schema.Facets {
+	facets := new(schema.Facets)
+	sources := make(map[string]int)
+	categories := make(map[string]int)
+	prices := make(map[string]int)
+	for _, item := range found {
+		sources[item.Source]++
+		if item.Category != "" {
+			categories[item.Category]++
+		}
+		prices[bucket(item.Price)]++
+		if item.OnSale {
+			facets.OnSale++
+		}
+	}
+
+	facets.Source = facetCounts(sources, 0)
+	facets.Category = facetCounts(categories, maxCategoryFacets)
+	for idx := range PriceBuckets {
+		if count := prices[bucketLabel(idx)]; count > 0 {
+			facets.Price = append(facets.Price, schema.FacetCount{Value: bucketLabel(idx), Count: count})
+		}
+	}
+	return facets
+}
+
+// facetCounts most hits first, at most limit, zero for all
+func facetCounts(counts map[string]int, limit int) []schema.FacetCount {
+	var result []schema.FacetCount
+	for value, count := range counts {
+		result = append(result, schema.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// SuggestFrom names of available items and brands completing text, for suggestions outside
+// the database, brand of item by brand, empty for none. Score is 1 when text start a word,
+// otherwise trigram similarity, weighted by 1 + ln(count)
+func SuggestFrom(items []schema.Item, brand func(schema.Item) string, text string, limit int) []schema.Suggestion {
+	text = strings.ToLower(text)
+	names := make(map[string]*schema.Suggestion)
+	brands := make(map[string]*schema.Suggestion)
+
+	// add count one hit of words into group by key, if words complete text
+	add := func(group map[string]*schema.Suggestion, key, kind, words string) {
+		weight := 1.0
+		if !strings.HasPrefix(words, text) && !strings.Contains(words, " "+text) {
+			if weight = similarity(words, text); weight < minSimilarity {
+				return
+			}
+		}
+		s, ok := group[key]
+		if !ok {
+			s = &schema.Suggestion{Text: key, Kind: kind}
+			group[key] = s
+		}
+		s.Count++
+		if weight > s.Score {
+			s.Score = weight
+		}
+	}
+
+	for _, item := range items {
+		if !item.Available {
+			continue
+		}
+		add(names, item.Name, "name", item.Tokens)
+		if b := brand(item); b != "" {
+			add(brands, b, "brand", strings.ToLower(b))
+		}
+	}
+
+	var result []schema.Suggestion
+	for _, group := range []map[string]*schema.Suggestion{names, brands} {
+		for _, s := range group {
+			s.Score *= 1 + math.Log(float64(s.Count))
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Text < result[j].Text
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// containsFold substr in s, case insensitive as ILIKE
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// containsAll every keyword in s
+func containsAll(s string, keywords []string) bool {
+	for _, kw := range keywords {
+		if !containsFold(s, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny one of keywords in s
+func containsAny(s string, keywords []string) bool {
+	for _, kw := range keywords {
+		if containsFold(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// less order of search sort, unknown unit price last, then cheapest and by id,
+// the same as orderBy and sortKeys of Postgres
+func less(sort string, a, b schema.Item) bool {
+	switch {
+	case sort == "price_desc" && a.Price != b.Price:
+		return a.Price > b.Price
+	case sort == "unit_price" && a.UnitPrice != b.UnitPrice:
+		if a.UnitPrice == 0 || b.UnitPrice == 0 {
+			return b.UnitPrice == 0
+		}
+		return a.UnitPrice < b.UnitPrice
+	case sort == "updated" && !a.Updated.Equal(b.Updated):
+		return a.Updated.After(b.Updated)
+	case sort == "drop" && a.Diff != b.Diff:
+		return a.Diff < b.Diff
+	case sort == "relevance" && a.Score != b.Score:
+		return a.Score > b.Score
+	}
+	if a.Price != b.Price {
+		return a.Price < b.Price
+	}
+	return a.Id < b.Id
+}
+
+// page slice items as LIMIT OFFSET
+func page(items []schema.Item, page, perPage int) []schema.Item {
+	offset := (page - 1) * perPage
+	if offset < 0 || offset >= len(items) {
+		return nil
+	}
+	end := offset + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
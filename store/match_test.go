@@ -0,0 +1,32 @@
+package store
+
+import (
+	"testing"
+
+	"honestman/schema"
+)
+
+func TestMatches(t *testing.T) {
+	zero, hundred := 0, 100
+	item := schema.Item{Name: "桂格燕麥片", Tokens: "桂格 燕麥 片 桂格燕麥片", Price: 100, Source: "RTmart"}
+
+	tests := []struct {
+		name string
+		q    SearchQuery
+		want bool
+	}{
+		{"keyword", SearchQuery{Keywords: []string{"燕麥"}}, true},
+		{"across words", SearchQuery{Keywords: []string{"麥片"}}, true},
+		{"exclude across words", SearchQuery{Excludes: []string{"麥片"}}, false},
+		{"any of", SearchQuery{AnyOf: [][]string{{"牛奶", "桂格"}}}, true},
+		{"source", SearchQuery{Source: "Carrefour"}, false},
+		{"max price", SearchQuery{MaxPrice: &hundred}, true},
+		{"max zero", SearchQuery{MaxPrice: &zero}, false},
+		{"min price", SearchQuery{MinPrice: &hundred}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Matches(item, nil); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
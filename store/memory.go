@@ -0,0 +1,727 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"honestman/schema"
+	"honestman/segment"
+)
+
+// Memory repository in memory, for test without database
+type Memory struct {
+	mu       sync.RWMutex
+	items    []schema.Item
+	history  []schema.PriceHistory
+	runs     []schema.CrawlRun
+	products []schema.Product
+	cats     []schema.Category
+	watches  map[int]schema.Watch
+	watchSeq int
+	dead     []schema.DeadLetter
+}
+
+// NewMemory new empty repository
+func NewMemory() *Memory {
+	return new(Memory)
+}
+
+// Upsert item by (source, url)
+func (s *Memory) Upsert(item *schema.Item, obs Observation) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := Inserted
+	item.Available = true
+	item.LastSeen = item.Updated
+	item.Tokens = segment.Tokens(item.Name)
+	idx := s.indexByURL(item.Source, item.Url)
+	if idx < 0 {
+		item.Id = len(s.items) + 1
+		item.Diff = 0
+		s.items = append(s.items, *item)
+	} else {
+		orig := s.items[idx]
+		item.Id = orig.Id
+		item.Created = orig.Created
+		item.ProductId = orig.ProductId
+		if item.CategoryId == nil {
+			// keep the category when crawled from the whole listing
+			item.Category, item.CategoryId = orig.Category, orig.CategoryId
+		}
+		item.Diff = item.Price - orig.Price
+		result = Unchanged
+		if item.Diff != 0 {
+			result = Updated
+		}
+		s.items[idx] = *item
+	}
+
+	h := schema.PriceHistory{
+		Id:           len(s.history) + 1,
+		ItemId:       item.Id,
+		Price:        item.Price,
+		SpecialPrice: item.PromoPrice,
+		ObservedAt:   item.Updated,
+	}
+	if obs.CrawlRunId > 0 {
+		runID := obs.CrawlRunId
+		h.CrawlRunId = &runID
+	}
+	s.history = append(s.history, h)
+	return result, nil
+}
+
+// MarkUnavailable items of source not seen since
+func (s *Memory) MarkUnavailable(source string, since time.Time, categories []int) ([]schema.Item, error) {
+	var marked []schema.Item
+
+	in := func(item *schema.Item) bool {
+		if categories == nil {
+			return true
+		}
+		for _, id := range categories {
+			if item.CategoryId != nil && *item.CategoryId == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx := range s.items {
+		item := &s.items[idx]
+		if item.Source == source && item.Available && item.LastSeen.Before(since) && in(item) {
+			item.Available = false
+			item.Updated = time.Now()
+			marked = append(marked, *item)
+		}
+	}
+	return marked, nil
+}
+
+func (s *Memory) indexByURL(source, url string) int {
+	for idx := range s.items {
+		if s.items[idx].Source == source && s.items[idx].Url == url {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Get item by id
+func (s *Memory) Get(id int) (*schema.Item, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id < 1 || id > len(s.items) {
+		return nil, ErrNotFound
+	}
+	item := s.items[id-1]
+	return &item, nil
+}
+
+// Sources stores having items, by name
+func (s *Memory) Sources() ([]schema.Source, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sources []schema.Source
+	at := make(map[string]int)
+	for _, item := range s.items {
+		idx, ok := at[item.Source]
+		if !ok {
+			idx = len(sources)
+			at[item.Source] = idx
+			sources = append(sources, schema.Source{Name: item.Source})
+		}
+		src := &sources[idx]
+		src.Items++
+		if item.Available {
+			src.Available++
+		}
+		if item.Updated.After(src.Updated) {
+			src.Updated = item.Updated
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources, nil
+}
+
+// GetByURL item of source by url
+func (s *Memory) GetByURL(source, url string) (*schema.Item, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := s.indexByURL(source, url)
+	if idx < 0 {
+		return nil, ErrNotFound
+	}
+	item := s.items[idx]
+	return &item, nil
+}
+
+// Search items whose name contain every keyword, cheapest first
+func (s *Memory) Search(q SearchQuery) ([]schema.Item, int, error) {
+	found := s.matches(q)
+	return Rank(found, q), len(found), nil
+}
+
+// Facets of every hit of q, most hits first
+func (s *Memory) Facets(q SearchQuery) (*schema.Facets, error) {
+	return CountFacets(s.matches(q)), nil
+}
+
+// Suggest names and brands completing text, brand of the product of item
+func (s *Memory) Suggest(text string, limit int) ([]schema.Suggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	brand := func(item schema.Item) string {
+		if item.ProductId == nil || *item.ProductId < 1 || *item.ProductId > len(s.products) {
+			return ""
+		}
+		return s.products[*item.ProductId-1].Brand
+	}
+	return SuggestFrom(s.items, brand, text, limit), nil
+}
+
+// matches every item of q
+func (s *Memory) matches(q SearchQuery) []schema.Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sub map[int]bool
+	if q.Category > 0 {
+		sub = s.subCategories(q.Category)
+	}
+	var found []schema.Item
+	for _, item := range s.items {
+		if q.Matches(item, sub) {
+			found = append(found, item)
+		}
+	}
+	return found
+}
+
+// subCategories ids of category and all its descendants, caller hold the lock
+func (s *Memory) subCategories(id int) map[int]bool {
+	sub := map[int]bool{id: true}
+	for grown := true; grown; {
+		grown = false
+		for _, c := range s.cats {
+			if c.ParentId != nil && sub[*c.ParentId] && !sub[c.Id] {
+				sub[c.Id] = true
+				grown = true
+			}
+		}
+	}
+	return sub
+}
+
+// History price series of item, week start on Monday as postgres date_trunc
+func (s *Memory) History(itemID int, q HistoryQuery) ([]schema.PricePoint, error) {
+	var points []schema.PricePoint
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, h := range s.history {
+		if h.ItemId != itemID || h.ObservedAt.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !h.ObservedAt.Before(q.To) {
+			continue
+		}
+
+		period := h.ObservedAt
+		switch q.Period {
+		case "day":
+			period = truncateDay(period)
+		case "week":
+			period = truncateDay(period)
+			period = period.AddDate(0, 0, -((int(period.Weekday()) + 6) % 7))
+		}
+
+		// history is appended in time order
+		last := len(points) - 1
+		if q.Period != "" && last >= 0 && points[last].Period.Equal(period) {
+			p := &points[last]
+			if h.Price < p.Min {
+				p.Min = h.Price
+			}
+			if h.Price > p.Max {
+				p.Max = h.Price
+			}
+			p.Avg = (p.Avg*float64(p.Count) + float64(h.Price)) / float64(p.Count+1)
+			p.Last = h.Price
+			p.Count++
+			continue
+		}
+		points = append(points, schema.PricePoint{
+			Period: period,
+			Min:    h.Price,
+			Max:    h.Price,
+			Avg:    float64(h.Price),
+			Last:   h.Price,
+			Count:  1,
+		})
+	}
+	return points, nil
+}
+
+// Changes of item price, newest first
+func (s *Memory) Changes(itemID int, limit int) ([]schema.PriceChange, error) {
+	var changes []schema.PriceChange
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// history is appended in time order
+	previous := -1
+	for _, h := range s.history {
+		if h.ItemId != itemID {
+			continue
+		}
+		if previous >= 0 && h.Price != previous {
+			changes = append(changes, schema.PriceChange{
+				Price:    h.Price,
+				Previous: previous,
+				Diff:     h.Price - previous,
+				Changed:  h.ObservedAt,
+			})
+		}
+		previous = h.Price
+	}
+
+	for i, j := 0, len(changes)-1; i < j; i, j = i+1, j-1 {
+		changes[i], changes[j] = changes[j], changes[i]
+	}
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// Movers available items moved the most in percent since q.Since, the biggest first
+func (s *Memory) Movers(q MoverQuery) ([]schema.Mover, error) {
+	var movers []schema.Mover
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// the last price observed before since, history is appended in time order
+	from := make(map[int]int)
+	for _, h := range s.history {
+		if !h.ObservedAt.After(q.Since) {
+			from[h.ItemId] = h.Price
+		}
+	}
+
+	var sub map[int]bool
+	if q.Category > 0 {
+		sub = s.subCategories(q.Category)
+	}
+	for _, item := range s.items {
+		price, ok := from[item.Id]
+		if !ok || price <= 0 || item.Price <= 0 || !item.Available || (q.Source != "" && item.Source != q.Source) {
+			continue
+		}
+		if sub != nil && (item.CategoryId == nil || !sub[*item.CategoryId]) {
+			continue
+		}
+		if change := item.Price - price; (q.Rise && change > 0) || (!q.Rise && change < 0) {
+			movers = append(movers, schema.Mover{
+				Item:    item,
+				From:    price,
+				Change:  change,
+				Percent: math.Round(float64(change)*1000/float64(price)) / 10,
+			})
+		}
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		if q.Rise {
+			return movers[i].Percent > movers[j].Percent
+		}
+		return movers[i].Percent < movers[j].Percent
+	})
+	if len(movers) > q.Limit {
+		movers = movers[:q.Limit]
+	}
+	return movers, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartRun record a running run of task
+func (s *Memory) StartRun(task string) (*schema.CrawlRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := schema.CrawlRun{
+		Id:      len(s.runs) + 1,
+		Task:    task,
+		Status:  schema.RunRunning,
+		Started: time.Now().Truncate(time.Second),
+	}
+	s.runs = append(s.runs, run)
+	return &run, nil
+}
+
+// FinishRun save counters and status of run
+func (s *Memory) FinishRun(run *schema.CrawlRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if run.Id < 1 || run.Id > len(s.runs) {
+		return ErrNotFound
+	}
+	now := time.Now().Truncate(time.Second)
+	run.Finished = &now
+	s.runs[run.Id-1] = *run
+	return nil
+}
+
+// GetRun run by id
+func (s *Memory) GetRun(id int) (*schema.CrawlRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id < 1 || id > len(s.runs) {
+		return nil, ErrNotFound
+	}
+	run := s.runs[id-1]
+	return &run, nil
+}
+
+// ListRuns runs newest first
+func (s *Memory) ListRuns(q RunQuery) ([]schema.CrawlRun, error) {
+	var runs []schema.CrawlRun
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for idx := len(s.runs) - 1; idx >= 0 && len(runs) < q.Limit; idx-- {
+		run := s.runs[idx]
+		if (q.Task == "" || run.Task == q.Task) && (q.Status == "" || run.Status == q.Status) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// LastSuccess the last successful run of every task
+func (s *Memory) LastSuccess() ([]schema.CrawlRun, error) {
+	var runs []schema.CrawlRun
+	seen := make(map[string]bool)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for idx := len(s.runs) - 1; idx >= 0; idx-- {
+		run := s.runs[idx]
+		if run.Status == schema.RunSuccess && !seen[run.Task] {
+			seen[run.Task] = true
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Task < runs[j].Task })
+	return runs, nil
+}
+
+// Unmatched items not in any product yet
+func (s *Memory) Unmatched(limit int) ([]schema.Item, error) {
+	var items []schema.Item
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, item := range s.items {
+		if item.ProductId == nil && len(items) < limit {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Similar matched items of other sources by trigram similarity as pg_trgm
+func (s *Memory) Similar(item schema.Item, name string, minScore float64, limit int) ([]schema.Item, error) {
+	var items []schema.Item
+	var scores = make(map[int]float64)
+
+	s.mu.RLock()
+	for _, other := range s.items {
+		if other.Source == item.Source || other.Unit != item.Unit || other.ProductId == nil {
+			continue
+		}
+		if score := similarity(other.Name, name); score >= minScore {
+			scores[other.Id] = score
+			items = append(items, other)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(items, func(i, j int) bool { return scores[items[i].Id] > scores[items[j].Id] })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// trigrams of every word padded with two spaces in front and one after
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		r := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// similarity shared trigrams over all trigrams
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	all := len(ta) + len(tb) - shared
+	if all == 0 {
+		return 0
+	}
+	return float64(shared) / float64(all)
+}
+
+// CreateProduct insert product
+func (s *Memory) CreateProduct(p *schema.Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.Created.IsZero() {
+		p.Created = time.Now().Truncate(time.Second)
+	}
+	p.Id = len(s.products) + 1
+	s.products = append(s.products, *p)
+	return nil
+}
+
+// SetProduct put item into product
+func (s *Memory) SetProduct(itemID, productID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if itemID < 1 || itemID > len(s.items) {
+		return ErrNotFound
+	}
+	s.items[itemID-1].ProductId = &productID
+	return nil
+}
+
+// GetProduct product by id
+func (s *Memory) GetProduct(id int) (*schema.Product, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id < 1 || id > len(s.products) {
+		return nil, ErrNotFound
+	}
+	p := s.products[id-1]
+	return &p, nil
+}
+
+// Offers items of product, cheapest first
+func (s *Memory) Offers(productID int) ([]schema.Item, error) {
+	var items []schema.Item
+
+	s.mu.RLock()
+	for _, item := range s.items {
+		if item.ProductId != nil && *item.ProductId == productID {
+			items = append(items, item)
+		}
+	}
+	s.mu.RUnlock()
+
+	// as ORDER BY price, source, id
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Id < b.Id
+	})
+	return items, nil
+}
+
+// CreateWatch insert watch
+func (s *Memory) CreateWatch(w *schema.Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watches == nil {
+		s.watches = make(map[int]schema.Watch)
+	}
+	if w.Created.IsZero() {
+		w.Created = time.Now().Truncate(time.Second)
+	}
+	s.watchSeq++
+	w.Id = s.watchSeq
+	s.watches[w.Id] = *w
+	return nil
+}
+
+// GetWatch watch by id
+func (s *Memory) GetWatch(id int) (*schema.Watch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	w, ok := s.watches[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &w, nil
+}
+
+// DeleteWatch remove watch
+func (s *Memory) DeleteWatch(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.watches[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.watches, id)
+	return nil
+}
+
+// MatchWatches watches item satisfies now
+func (s *Memory) MatchWatches(item schema.Item) ([]schema.Watch, error) {
+	var matched []schema.Watch
+
+	s.mu.RLock()
+	for _, w := range s.watches {
+		if w.Matches(item) {
+			matched = append(matched, w)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Id < matched[j].Id })
+	return matched, nil
+}
+
+// Notified remember when watch was notified
+func (s *Memory) Notified(id int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.watches[id]
+	if !ok {
+		return ErrNotFound
+	}
+	w.LastNotified = &at
+	s.watches[id] = w
+	return nil
+}
+
+// SaveCategory insert or update c by (source, code)
+func (s *Memory) SaveCategory(c *schema.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, orig := range s.cats {
+		if orig.Source == c.Source && orig.Code == c.Code {
+			c.Id, c.Created = orig.Id, orig.Created
+			s.cats[idx] = *c
+			return nil
+		}
+	}
+	if c.Created.IsZero() {
+		c.Created = time.Now().Truncate(time.Second)
+	}
+	c.Id = len(s.cats) + 1
+	s.cats = append(s.cats, *c)
+	return nil
+}
+
+// countItems available items of category c, caller hold the lock
+func (s *Memory) countItems(c *schema.Category) {
+	sub := s.subCategories(c.Id)
+	c.Items = 0
+	for _, item := range s.items {
+		if item.Available && item.CategoryId != nil && sub[*item.CategoryId] {
+			c.Items++
+		}
+	}
+}
+
+// GetCategory category by id, with item count
+func (s *Memory) GetCategory(id int) (*schema.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id < 1 || id > len(s.cats) {
+		return nil, ErrNotFound
+	}
+	c := s.cats[id-1]
+	s.countItems(&c)
+	return &c, nil
+}
+
+// Categories of source ordered by path, with item count, empty source for all
+func (s *Memory) Categories(source string) ([]schema.Category, error) {
+	var categories []schema.Category
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, c := range s.cats {
+		if source != "" && c.Source != source {
+			continue
+		}
+		s.countItems(&c)
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Source != categories[j].Source {
+			return categories[i].Source < categories[j].Source
+		}
+		return categories[i].Path < categories[j].Path
+	})
+	return categories, nil
+}
+
+// SaveDeadLetter insert undelivered webhook event
+func (s *Memory) SaveDeadLetter(d *schema.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d.Created.IsZero() {
+		d.Created = time.Now().Truncate(time.Second)
+	}
+	d.Id = len(s.dead) + 1
+	s.dead = append(s.dead, *d)
+	return nil
+}
+
+// DeadLetters every saved undelivered event
+func (s *Memory) DeadLetters() []schema.DeadLetter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]schema.DeadLetter(nil), s.dead...)
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"honestman/schema"
+)
+
+func TestOffers(t *testing.T) {
+	mem := NewMemory()
+	p := schema.Product{Name: "光泉 鮮乳"}
+	if err := mem.CreateProduct(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	// same price at two sources, and twice at one source
+	offers := []struct {
+		source string
+		price  int
+	}{{"RTmart", 90}, {"Carrefour", 90}, {"RTmart", 80}, {"RTmart", 90}}
+	for i, o := range offers {
+		item := schema.Item{Source: o.source, Url: fmt.Sprint(i), Name: "光泉 鮮乳", Price: o.price}
+		if _, err := mem.Upsert(&item, Observation{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := mem.SetProduct(item.Id, p.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := mem.Offers(p.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(ids(items)), "[3 2 1 4]"; got != want {
+		t.Errorf("offers %s, want %s by price, source and id", got, want)
+	}
+}
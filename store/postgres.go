@@ -0,0 +1,719 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"honestman/schema"
+	"honestman/segment"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Postgres repository on PostgreSQL
+type Postgres struct {
+	DB *sqlx.DB
+}
+
+// NewPostgres new repository
+func NewPostgres(db *sqlx.DB) *Postgres {
+	return &Postgres{DB: db}
+}
+
+// Upsert item by (source, url), diff always against the last price
+func (s *Postgres) Upsert(item *schema.Item, obs Observation) (Result, error) {
+	var inserted bool
+
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return Unchanged, err
+	}
+	defer tx.Rollback()
+
+	item.Available = true
+	item.LastSeen = item.Updated
+	item.Tokens = segment.Tokens(item.Name)
+
+	// xmax = 0 only for a fresh inserted row
+	err = tx.QueryRowx(`INSERT INTO item
+	(price, diff, regular_price, promo_price, on_sale, pack_qty, quantity, unit, unit_price,
+	name, url, imgsrc, source, note, category, category_id, created, updated, available, last_seen, tokens) VALUES
+	($1, 0, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, true, $17, $18)
+	ON CONFLICT (source, url) DO UPDATE SET
+	diff = EXCLUDED.price - item.price,
+	price = EXCLUDED.price,
+	regular_price = EXCLUDED.regular_price,
+	promo_price = EXCLUDED.promo_price,
+	on_sale = EXCLUDED.on_sale,
+	pack_qty = EXCLUDED.pack_qty,
+	quantity = EXCLUDED.quantity,
+	unit = EXCLUDED.unit,
+	unit_price = EXCLUDED.unit_price,
+	name = EXCLUDED.name,
+	tokens = EXCLUDED.tokens,
+	imgsrc = EXCLUDED.imgsrc,
+	note = EXCLUDED.note,
+	-- keep the category when crawled from the whole listing
+	category = CASE WHEN EXCLUDED.category_id IS NULL THEN item.category ELSE EXCLUDED.category END,
+	category_id = coalesce(EXCLUDED.category_id, item.category_id),
+	updated = EXCLUDED.updated,
+	available = true,
+	last_seen = EXCLUDED.last_seen
+	RETURNING id, diff, xmax = 0`,
+		item.Price, item.RegularPrice, item.PromoPrice, item.OnSale, item.PackQty, item.Quantity, item.Unit, item.UnitPrice,
+		item.Name, item.Url, item.Imgsrc, item.Source, item.Note, item.Category, item.CategoryId,
+		item.Created, item.Updated, item.Tokens,
+	).Scan(&item.Id, &item.Diff, &inserted)
+	if err != nil {
+		return Unchanged, err
+	}
+
+	runID := sql.NullInt64{Int64: int64(obs.CrawlRunId), Valid: obs.CrawlRunId > 0}
+	_, err = tx.Exec(`INSERT INTO price_history
+	(item_id, price, special_price, observed_at, crawl_run_id) VALUES ($1, $2, $3, $4, $5)`,
+		item.Id, item.Price, item.PromoPrice, item.Updated, runID)
+	if err != nil {
+		return Unchanged, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Unchanged, err
+	}
+
+	switch {
+	case inserted:
+		return Inserted, nil
+	case item.Diff != 0:
+		return Updated, nil
+	}
+	return Unchanged, nil
+}
+
+// MarkUnavailable items of source not seen since
+func (s *Postgres) MarkUnavailable(source string, since time.Time, categories []int) ([]schema.Item, error) {
+	var items []schema.Item
+
+	args := []interface{}{source, since}
+	where := "source = $1 AND available AND last_seen < $2"
+	if categories != nil {
+		ids := make([]int64, len(categories))
+		for i, id := range categories {
+			ids[i] = int64(id)
+		}
+		args = append(args, pq.Array(ids))
+		where += " AND category_id = ANY($3)"
+	}
+	err := s.DB.Select(&items, `UPDATE item SET available = false, updated = NOW()
+	WHERE `+where+` RETURNING *`, args...)
+	return items, err
+}
+
+// Get item by id
+func (s *Postgres) Get(id int) (*schema.Item, error) {
+	item := new(schema.Item)
+	err := s.DB.Get(item, "SELECT * FROM item WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return item, err
+}
+
+// Sources stores having items, by name
+func (s *Postgres) Sources() ([]schema.Source, error) {
+	var sources []schema.Source
+	err := s.DB.Select(&sources, `SELECT source AS name, count(*) AS items,
+	count(*) FILTER (WHERE available) AS available, max(updated) AS updated
+	FROM item GROUP BY source ORDER BY source`)
+	return sources, err
+}
+
+// GetByURL item of source by url
+func (s *Postgres) GetByURL(source, url string) (*schema.Item, error) {
+	item := new(schema.Item)
+	err := s.DB.Get(item, "SELECT * FROM item WHERE source = $1 AND url = $2", source, url)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return item, err
+}
+
+// searchWhere conditions of q and their args
+func searchWhere(q SearchQuery) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	// arg append v and return its placeholder
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, kw := range q.Keywords {
+		where = append(where, "tokens ILIKE "+arg(contains(kw)))
+	}
+	for _, group := range q.AnyOf {
+		var alts []string
+		for _, kw := range group {
+			alts = append(alts, "tokens ILIKE "+arg(contains(kw)))
+		}
+		where = append(where, "("+strings.Join(alts, " OR ")+")")
+	}
+	for _, kw := range q.Excludes {
+		where = append(where, "tokens NOT ILIKE "+arg(contains(kw)))
+	}
+	if q.OnSale {
+		where = append(where, "on_sale")
+	}
+	if q.Available != nil {
+		where = append(where, "available = "+arg(*q.Available))
+	}
+	if q.Unit != "" {
+		where = append(where, "unit = "+arg(q.Unit))
+	}
+	if q.Source != "" {
+		where = append(where, "source = "+arg(q.Source))
+	}
+	if q.MinPrice != nil {
+		where = append(where, "price >= "+arg(*q.MinPrice))
+	}
+	if q.MaxPrice != nil {
+		where = append(where, "price <= "+arg(*q.MaxPrice))
+	}
+	if q.Category > 0 {
+		args = append(args, q.Category)
+		where = append(where, fmt.Sprintf("category_id IN (%s)", subCategories(len(args))))
+	}
+	if q.CategoryPath != "" {
+		where = append(where, "strpos(lower(category), lower("+arg(q.CategoryPath)+")) > 0")
+	}
+	return where, args
+}
+
+// Search items whose name match every keyword, cheapest first
+func (s *Postgres) Search(q SearchQuery) ([]schema.Item, int, error) {
+	var items []schema.Item
+	var count int
+
+	where, args := searchWhere(q)
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	// arg append v and return its placeholder
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	err := s.DB.Get(&count, fmt.Sprintf("SELECT count(*) as count FROM item %s", cond), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// args after count, postgres refuse unused parameters;
+	// score as float8, the real of similarity and ts_rank would never equal the cursor score again
+	score := "0::float8"
+	if text := q.Text(); text != "" {
+		score = fmt.Sprintf("similarity(tokens, %s)::float8", arg(text))
+		if q.Rank == "fulltext" {
+			// tokens are words split by space, any word of text counts, the more the higher
+			score = fmt.Sprintf("ts_rank(to_tsvector('simple', tokens), to_tsquery('simple', %s))::float8", arg(orQuery(text)))
+		}
+	}
+
+	// handle paging, by keyset after or before the cursor, or by offset
+	keys := keysOf(q.Sort)
+	seek, reverse, offset := "", false, (q.Page-1)*q.PerPage
+	if q.Cursor != nil {
+		seek = "WHERE " + keyset(keys, q.Cursor, arg)
+		reverse, offset = q.Cursor.Before, 0
+	}
+	limitoffset := fmt.Sprintf("LIMIT %s OFFSET %s", arg(q.PerPage), arg(offset))
+
+	err = s.DB.Select(&items, fmt.Sprintf("SELECT * FROM (SELECT *, %s AS score FROM item %s) AS hit %s ORDER BY %s %s",
+		score, cond, seek, orderBy(keys, reverse), limitoffset), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if reverse {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, count, nil
+}
+
+// Facets of every hit of q, most hits first
+func (s *Postgres) Facets(q SearchQuery) (*schema.Facets, error) {
+	facets := new(schema.Facets)
+
+	where, args := searchWhere(q)
+	// cond of q and more
+	cond := func(more ...string) string {
+		all := append(append([]string(nil), where...), more...)
+		if len(all) == 0 {
+			return ""
+		}
+		return "WHERE " + strings.Join(all, " AND ")
+	}
+
+	err := s.DB.Select(&facets.Source, fmt.Sprintf(`SELECT source AS value, count(*) AS count
+	FROM item %s GROUP BY 1 ORDER BY 2 DESC, 1`, cond()), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Select(&facets.Category, fmt.Sprintf(`SELECT category AS value, count(*) AS count
+	FROM item %s GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT %d`, cond("category <> ''"), maxCategoryFacets), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// one CASE branch of each bucket, the highest first
+	var buckets []string
+	for idx := len(PriceBuckets) - 1; idx > 0; idx-- {
+		buckets = append(buckets, fmt.Sprintf("WHEN price >= %d THEN %d", PriceBuckets[idx], idx))
+	}
+	var counts []struct {
+		Bucket int `db:"bucket"`
+		Count  int `db:"count"`
+	}
+	err = s.DB.Select(&counts, fmt.Sprintf(`SELECT CASE %s ELSE 0 END AS bucket, count(*) AS count
+	FROM item %s GROUP BY 1 ORDER BY 1`, strings.Join(buckets, " "), cond()), args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		facets.Price = append(facets.Price, schema.FacetCount{Value: bucketLabel(c.Bucket), Count: c.Count})
+	}
+
+	err = s.DB.Get(&facets.OnSale, fmt.Sprintf("SELECT count(*) FROM item %s", cond("on_sale")), args...)
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// Suggest names and brands completing text, score as SuggestFrom
+func (s *Postgres) Suggest(text string, limit int) ([]schema.Suggestion, error) {
+	var suggestions []schema.Suggestion
+
+	text = strings.ToLower(text)
+	prefix := likeEscaper.Replace(text) + "%"
+	err := s.DB.Select(&suggestions, `SELECT text, kind, count, weight * (1 + ln(count)) AS score FROM (
+		SELECT name AS text, 'name' AS kind, count(*)::float AS count,
+		max(CASE WHEN tokens LIKE $1 OR tokens LIKE $2 THEN 1 ELSE similarity(tokens, $3) END) AS weight
+		FROM item WHERE available AND (tokens LIKE $1 OR tokens LIKE $2 OR tokens % $3)
+		GROUP BY name
+		UNION ALL
+		SELECT product.brand, 'brand', count(*)::float,
+		max(CASE WHEN lower(product.brand) LIKE $1 OR lower(product.brand) LIKE $2 THEN 1
+		ELSE similarity(lower(product.brand), $3) END)
+		FROM item JOIN product ON item.product_id = product.id
+		WHERE item.available AND product.brand <> ''
+		AND (lower(product.brand) LIKE $1 OR lower(product.brand) LIKE $2 OR lower(product.brand) % $3)
+		GROUP BY product.brand
+	) AS suggestion ORDER BY score DESC, text LIMIT $4`, prefix, "% "+prefix, text, limit)
+	return suggestions, err
+}
+
+// orQuery tsquery of any word of text, each word quoted as a literal
+func orQuery(text string) string {
+	var words []string
+	for _, w := range strings.Fields(text) {
+		w = strings.Replace(strings.Replace(w, `\`, `\\`, -1), "'", "''", -1)
+		words = append(words, "'"+w+"'")
+	}
+	return strings.Join(words, " | ")
+}
+
+// likeEscaper escape LIKE wildcards, keywords are literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// contains ILIKE pattern of kw anywhere, served by the gin_trgm_ops index of tokens
+func contains(kw string) string {
+	return "%" + likeEscaper.Replace(kw) + "%"
+}
+
+// subCategories ids of category $n and all its descendants
+func subCategories(n int) string {
+	return fmt.Sprintf(`WITH RECURSIVE sub AS (
+	SELECT id FROM category WHERE id = $%d
+	UNION ALL
+	SELECT category.id FROM category JOIN sub ON category.parent_id = sub.id
+	) SELECT id FROM sub`, n)
+}
+
+// sortKey one column of search order
+type sortKey struct {
+	expr  string
+	desc  bool
+	cast  string // of cursor value
+	value func(c *Cursor) interface{}
+}
+
+var (
+	byPrice = sortKey{expr: "price", value: func(c *Cursor) interface{} { return c.Price }}
+	byID    = sortKey{expr: "id", value: func(c *Cursor) interface{} { return c.Id }}
+)
+
+// sortKeys of each search sort, unknown unit price last, then cheapest and by id, the same as less
+var sortKeys = map[string][]sortKey{
+	"price":      {byPrice, byID},
+	"price_desc": {{expr: "price", desc: true, value: func(c *Cursor) interface{} { return c.Price }}, byID},
+	"unit_price": {
+		{expr: "unit_price = 0", value: func(c *Cursor) interface{} { return c.UnitPrice == 0 }},
+		{expr: "unit_price", value: func(c *Cursor) interface{} { return c.UnitPrice }},
+		byPrice, byID,
+	},
+	"updated": {
+		// timestamp without time zone, compare as written
+		{expr: "updated", desc: true, cast: "::timestamp", value: func(c *Cursor) interface{} {
+			return c.Updated.Format("2006-01-02 15:04:05.999999")
+		}},
+		byPrice, byID,
+	},
+	"drop":      {{expr: "diff", value: func(c *Cursor) interface{} { return c.Diff }}, byPrice, byID},
+	"relevance": {{expr: "score", desc: true, value: func(c *Cursor) interface{} { return c.Score }}, byPrice, byID},
+}
+
+// keysOf sort, price by default
+func keysOf(sort string) []sortKey {
+	if keys, ok := sortKeys[sort]; ok {
+		return keys
+	}
+	return sortKeys["price"]
+}
+
+// orderBy of keys, reverse for the items before a cursor
+func orderBy(keys []sortKey, reverse bool) string {
+	var order []string
+	for _, k := range keys {
+		if k.desc != reverse {
+			order = append(order, k.expr+" DESC")
+		} else {
+			order = append(order, k.expr)
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+// keyset condition of items after, or before, cursor c in order of keys
+func keyset(keys []sortKey, c *Cursor, arg func(interface{}) string) string {
+	var ors []string
+	for idx, k := range keys {
+		var ands []string
+		for _, eq := range keys[:idx] {
+			ands = append(ands, fmt.Sprintf("(%s) = %s%s", eq.expr, arg(eq.value(c)), eq.cast))
+		}
+		op := "<"
+		if k.desc == c.Before {
+			op = ">"
+		}
+		ands = append(ands, fmt.Sprintf("(%s) %s %s%s", k.expr, op, arg(k.value(c)), k.cast))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+// historyFields period to postgres date_trunc field
+var historyFields = map[string]string{
+	"day":  "day",
+	"week": "week",
+}
+
+// History price series of item
+func (s *Postgres) History(itemID int, q HistoryQuery) ([]schema.PricePoint, error) {
+	var points []schema.PricePoint
+
+	from, to := q.From, q.To
+	if to.IsZero() {
+		to = time.Now().AddDate(100, 0, 0)
+	}
+
+	field, ok := historyFields[q.Period]
+	if !ok {
+		err := s.DB.Select(&points, `SELECT observed_at AS period,
+		price AS min, price AS max, price::float AS avg, price AS last, 1 AS count
+		FROM price_history WHERE item_id = $1 AND observed_at >= $2 AND observed_at < $3
+		ORDER BY observed_at`, itemID, from, to)
+		return points, err
+	}
+
+	err := s.DB.Select(&points, fmt.Sprintf(`SELECT date_trunc('%s', observed_at) AS period,
+	min(price) AS min, max(price) AS max, avg(price)::float AS avg,
+	(array_agg(price ORDER BY observed_at DESC))[1] AS last, count(*) AS count
+	FROM price_history WHERE item_id = $1 AND observed_at >= $2 AND observed_at < $3
+	GROUP BY 1 ORDER BY 1`, field), itemID, from, to)
+	return points, err
+}
+
+// Changes of item price, newest first
+func (s *Postgres) Changes(itemID int, limit int) ([]schema.PriceChange, error) {
+	var changes []schema.PriceChange
+	err := s.DB.Select(&changes, `SELECT price, previous, price - previous AS diff, observed_at AS changed
+	FROM (SELECT price, observed_at,
+		lag(price) OVER (ORDER BY observed_at, id) AS previous
+		FROM price_history WHERE item_id = $1) AS h
+	WHERE price <> previous
+	ORDER BY observed_at DESC LIMIT $2`, itemID, limit)
+	return changes, err
+}
+
+// Movers available items moved the most in percent since q.Since, by the last price observed
+// before it, items seen only after are not counted
+func (s *Postgres) Movers(q MoverQuery) ([]schema.Mover, error) {
+	var movers []schema.Mover
+
+	args := []interface{}{q.Since, q.Limit}
+	where := []string{"item.available", "item.price > 0", "h.price > 0", "item.price <> h.price"}
+	if q.Rise {
+		where = append(where, "item.price > h.price")
+	} else {
+		where = append(where, "item.price < h.price")
+	}
+	if q.Source != "" {
+		args = append(args, q.Source)
+		where = append(where, fmt.Sprintf("item.source = $%d", len(args)))
+	}
+	if q.Category > 0 {
+		args = append(args, q.Category)
+		where = append(where, fmt.Sprintf("item.category_id IN (%s)", subCategories(len(args))))
+	}
+	order := "percent, item.id"
+	if q.Rise {
+		order = "percent DESC, item.id"
+	}
+
+	err := s.DB.Select(&movers, fmt.Sprintf(`SELECT item.*, h.price AS from_price,
+	item.price - h.price AS change,
+	round((item.price - h.price) * 100.0 / h.price, 1)::float AS percent
+	FROM item JOIN LATERAL (SELECT price FROM price_history
+		WHERE item_id = item.id AND observed_at <= $1
+		ORDER BY observed_at DESC LIMIT 1) AS h ON true
+	WHERE %s ORDER BY %s LIMIT $2`, strings.Join(where, " AND "), order), args...)
+	return movers, err
+}
+
+// StartRun record a running run of task
+func (s *Postgres) StartRun(task string) (*schema.CrawlRun, error) {
+	run := &schema.CrawlRun{
+		Task:    task,
+		Status:  schema.RunRunning,
+		Started: time.Now().Truncate(time.Second),
+	}
+	err := s.DB.Get(&run.Id, `INSERT INTO crawl_run (task, status, started)
+	VALUES ($1, $2, $3) RETURNING id`, run.Task, run.Status, run.Started)
+	return run, err
+}
+
+// FinishRun save counters and status of run
+func (s *Postgres) FinishRun(run *schema.CrawlRun) error {
+	now := time.Now().Truncate(time.Second)
+	run.Finished = &now
+	_, err := s.DB.NamedExec(`UPDATE crawl_run SET
+	status=:status,
+	finished=:finished,
+	pages=:pages,
+	inserted=:inserted,
+	updated=:updated,
+	unchanged=:unchanged,
+	errors=:errors,
+	last_error=:last_error WHERE id=:id`, run)
+	return err
+}
+
+// GetRun run by id
+func (s *Postgres) GetRun(id int) (*schema.CrawlRun, error) {
+	run := new(schema.CrawlRun)
+	err := s.DB.Get(run, "SELECT * FROM crawl_run WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return run, err
+}
+
+// ListRuns runs newest first
+func (s *Postgres) ListRuns(q RunQuery) ([]schema.CrawlRun, error) {
+	var runs []schema.CrawlRun
+	var where []string
+	var args []interface{}
+
+	if q.Task != "" {
+		args = append(args, q.Task)
+		where = append(where, fmt.Sprintf("task = $%d", len(args)))
+	}
+	if q.Status != "" {
+		args = append(args, q.Status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, q.Limit)
+
+	err := s.DB.Select(&runs, fmt.Sprintf("SELECT * FROM crawl_run %s ORDER BY started DESC, id DESC LIMIT $%d", cond, len(args)), args...)
+	return runs, err
+}
+
+// LastSuccess the last successful run of every task
+func (s *Postgres) LastSuccess() ([]schema.CrawlRun, error) {
+	var runs []schema.CrawlRun
+	err := s.DB.Select(&runs, `SELECT DISTINCT ON (task) * FROM crawl_run
+	WHERE status = $1 ORDER BY task, started DESC`, schema.RunSuccess)
+	return runs, err
+}
+
+// Unmatched items not in any product yet
+func (s *Postgres) Unmatched(limit int) ([]schema.Item, error) {
+	var items []schema.Item
+	err := s.DB.Select(&items, "SELECT * FROM item WHERE product_id IS NULL ORDER BY id LIMIT $1", limit)
+	return items, err
+}
+
+// Similar matched items of other sources by trigram similarity, % use the item_name index
+func (s *Postgres) Similar(item schema.Item, name string, minScore float64, limit int) ([]schema.Item, error) {
+	var items []schema.Item
+	err := s.DB.Select(&items, `SELECT * FROM item
+	WHERE name % $1 AND similarity(name, $1) >= $2
+	AND source <> $3 AND unit = $4 AND product_id IS NOT NULL
+	ORDER BY similarity(name, $1) DESC LIMIT $5`, name, minScore, item.Source, item.Unit, limit)
+	return items, err
+}
+
+// CreateProduct insert product
+func (s *Postgres) CreateProduct(p *schema.Product) error {
+	if p.Created.IsZero() {
+		p.Created = time.Now().Truncate(time.Second)
+	}
+	return s.DB.Get(&p.Id, `INSERT INTO product (name, brand, quantity, unit, created)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id`, p.Name, p.Brand, p.Quantity, p.Unit, p.Created)
+}
+
+// SetProduct put item into product
+func (s *Postgres) SetProduct(itemID, productID int) error {
+	_, err := s.DB.Exec("UPDATE item SET product_id = $1 WHERE id = $2", productID, itemID)
+	return err
+}
+
+// GetProduct product by id
+func (s *Postgres) GetProduct(id int) (*schema.Product, error) {
+	p := new(schema.Product)
+	err := s.DB.Get(p, "SELECT * FROM product WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+// Offers items of product, cheapest first
+func (s *Postgres) Offers(productID int) ([]schema.Item, error) {
+	var items []schema.Item
+	err := s.DB.Select(&items, "SELECT * FROM item WHERE product_id = $1 ORDER BY price, source, id", productID)
+	return items, err
+}
+
+// CreateWatch insert watch
+func (s *Postgres) CreateWatch(w *schema.Watch) error {
+	if w.Created.IsZero() {
+		w.Created = time.Now().Truncate(time.Second)
+	}
+	return s.DB.Get(&w.Id, `INSERT INTO watch (item_id, query, target_price, channel, address, created, token_hash)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, w.ItemId, w.Query, w.TargetPrice, w.Channel, w.Address, w.Created, w.TokenHash)
+}
+
+// GetWatch watch by id
+func (s *Postgres) GetWatch(id int) (*schema.Watch, error) {
+	w := new(schema.Watch)
+	err := s.DB.Get(w, "SELECT * FROM watch WHERE id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+// DeleteWatch remove watch
+func (s *Postgres) DeleteWatch(id int) error {
+	res, err := s.DB.Exec("DELETE FROM watch WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MatchWatches watches item satisfies now, query keywords are checked by Watch.Matches
+func (s *Postgres) MatchWatches(item schema.Item) ([]schema.Watch, error) {
+	var watches, matched []schema.Watch
+	err := s.DB.Select(&watches, `SELECT * FROM watch
+	WHERE $1 > 0 AND target_price >= $1 AND (item_id = $2 OR (item_id IS NULL AND query <> ''))`, item.Price, item.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range watches {
+		if w.Matches(item) {
+			matched = append(matched, w)
+		}
+	}
+	return matched, nil
+}
+
+// Notified remember when watch was notified
+func (s *Postgres) Notified(id int, at time.Time) error {
+	_, err := s.DB.Exec("UPDATE watch SET last_notified = $1 WHERE id = $2", at, id)
+	return err
+}
+
+// SaveDeadLetter insert undelivered webhook event
+func (s *Postgres) SaveDeadLetter(d *schema.DeadLetter) error {
+	if d.Created.IsZero() {
+		d.Created = time.Now().Truncate(time.Second)
+	}
+	return s.DB.Get(&d.Id, `INSERT INTO webhook_dead_letter (url, event, payload, attempts, last_error, created)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, d.Url, d.Event, d.Payload, d.Attempts, d.LastError, d.Created)
+}
+
+// SaveCategory insert or update c by (source, code), fill c.Id
+func (s *Postgres) SaveCategory(c *schema.Category) error {
+	return s.DB.QueryRow(`INSERT INTO category (source, code, name, parent_id, path) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (source, code) DO UPDATE SET
+	name = EXCLUDED.name,
+	parent_id = EXCLUDED.parent_id,
+	path = EXCLUDED.path
+	RETURNING id, created`, c.Source, c.Code, c.Name, c.ParentId, c.Path).Scan(&c.Id, &c.Created)
+}
+
+// categorySelect category with its available items, sub categories included
+var categorySelect = `WITH RECURSIVE tree AS (
+	SELECT id, id AS root FROM category
+	UNION ALL
+	SELECT category.id, tree.root FROM category JOIN tree ON category.parent_id = tree.id
+)
+SELECT category.*, (SELECT count(*) FROM item JOIN tree ON item.category_id = tree.id
+	WHERE tree.root = category.id AND item.available) AS items
+FROM category`
+
+// GetCategory category by id, with item count
+func (s *Postgres) GetCategory(id int) (*schema.Category, error) {
+	c := new(schema.Category)
+	err := s.DB.Get(c, categorySelect+" WHERE category.id = $1", id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return c, err
+}
+
+// Categories of source ordered by path, with item count, empty source for all
+func (s *Postgres) Categories(source string) ([]schema.Category, error) {
+	var categories []schema.Category
+	err := s.DB.Select(&categories, categorySelect+` WHERE $1 = '' OR category.source = $1
+	ORDER BY category.source, category.path`, source)
+	return categories, err
+}
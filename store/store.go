@@ -0,0 +1,231 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"honestman/schema"
+)
+
+// ErrNotFound no such row
+var ErrNotFound = errors.New("store: not found")
+
+// Result what Upsert did to the item
+type Result int
+
+const (
+	// Unchanged item existed with the same price
+	Unchanged Result = iota
+	// Inserted new item
+	Inserted
+	// Updated item existed and price changed
+	Updated
+	// Delisted item not seen in recent crawls, marked unavailable
+	Delisted
+)
+
+func (r Result) String() string {
+	switch r {
+	case Inserted:
+		return "inserted"
+	case Updated:
+		return "updated"
+	case Delisted:
+		return "delisted"
+	}
+	return "unchanged"
+}
+
+// Observation extra of one crawl of item, kept in price history
+type Observation struct {
+	CrawlRunId int // zero when the run is not recorded
+}
+
+// SearchQuery what to search in item
+type SearchQuery struct {
+	Keywords     []string   // every keyword or phrase should be in name, case insensitive
+	AnyOf        [][]string // one keyword of each group should be in name
+	Excludes     []string   // no keyword should be in name
+	OnSale       bool       // only items on sale
+	Available    *bool      // only available or unavailable items, nil for both
+	Unit         string     // only items of unit g, ml or pc
+	Source       string     // only items of source, empty for all
+	MinPrice     *int       // inclusive, nil for no lower bound
+	MaxPrice     *int       // inclusive, nil for no upper bound
+	Category     int        // only items in category or its sub categories, zero for all
+	CategoryPath string     // only items whose category path contains it
+	Sort         string     // one of Sorts, "price" by default
+	Rank         string     // score of hits, "trigram" similarity (default) or "fulltext"
+	Page         int        // start from 1, ignored with Cursor
+	PerPage      int
+	Cursor       *Cursor // keyset paging, nil for Page
+}
+
+// Sorts orders of search
+var Sorts = []string{"price", "price_desc", "unit_price", "updated", "drop", "relevance"}
+
+// Ranks scores of hits, the first by default
+var Ranks = []string{"trigram", "fulltext"}
+
+// Filtered any keyword or filter given, search the whole table otherwise
+func (q SearchQuery) Filtered() bool {
+	return len(q.Keywords) > 0 || len(q.AnyOf) > 0 || len(q.Excludes) > 0 || q.OnSale ||
+		q.Unit != "" || q.Source != "" || q.MinPrice != nil || q.MaxPrice != nil ||
+		q.Category > 0 || q.CategoryPath != ""
+}
+
+// Text keywords as one string, compared with name for relevance
+func (q SearchQuery) Text() string {
+	words := append([]string(nil), q.Keywords...)
+	for _, group := range q.AnyOf {
+		words = append(words, group...)
+	}
+	return strings.Join(words, " ")
+}
+
+// HistoryQuery which part of price history
+type HistoryQuery struct {
+	Period string    // "" keep every observation, "day" or "week" aggregate
+	From   time.Time // inclusive, zero for no lower bound
+	To     time.Time // exclusive, zero for no upper bound
+}
+
+// MoverQuery which price movers
+type MoverQuery struct {
+	Since    time.Time // window start, compared with the last price observed before
+	Rise     bool      // rises, drops otherwise
+	Source   string    // empty for every source
+	Category int       // with sub categories, zero for every category
+	Limit    int
+}
+
+// ItemRepository persist items and their price history
+type ItemRepository interface {
+	// Upsert insert or update item by (source, url), fill item.Id and item.Diff
+	// and append one price history observation, item become available
+	Upsert(item *schema.Item, obs Observation) (Result, error)
+	// MarkUnavailable items of source not seen since, return the newly marked ones,
+	// updated of them is now; nil categories for every item of source,
+	// otherwise only items in one of categories
+	MarkUnavailable(source string, since time.Time, categories []int) ([]schema.Item, error)
+	// Get item by id
+	Get(id int) (*schema.Item, error)
+	// GetByURL item of source by url
+	GetByURL(source, url string) (*schema.Item, error)
+	// Search items, return one page and the total count
+	Search(q SearchQuery) ([]schema.Item, int, error)
+	// History price series of item
+	History(itemID int, q HistoryQuery) ([]schema.PricePoint, error)
+	// Changes of item price, newest first, limit of them
+	Changes(itemID int, limit int) ([]schema.PriceChange, error)
+	// Movers available items moved the most in percent since q.Since, the biggest first
+	Movers(q MoverQuery) ([]schema.Mover, error)
+	// Sources stores having items, by name
+	Sources() ([]schema.Source, error)
+}
+
+// Searcher search items, the database or the embedded index
+type Searcher interface {
+	// Search items, return one page and the total count
+	Search(q SearchQuery) ([]schema.Item, int, error)
+	// Facets of every hit of q, most hits first
+	Facets(q SearchQuery) (*schema.Facets, error)
+	// Suggest names and brands completing text, text already segmented
+	Suggest(text string, limit int) ([]schema.Suggestion, error)
+}
+
+// minSimilarity of a suggestion not completing text, as pg_trgm % operator
+const minSimilarity = 0.3
+
+// PriceBuckets lower bounds of price facet, the first from zero
+var PriceBuckets = []int{0, 50, 100, 200, 500, 1000}
+
+// maxCategoryFacets categories in facets at most
+const maxCategoryFacets = 20
+
+// bucket label of price, as price filter of query "50..99", the last "1000.."
+func bucket(price int) string {
+	for idx := len(PriceBuckets) - 1; idx >= 0; idx-- {
+		if price >= PriceBuckets[idx] {
+			return bucketLabel(idx)
+		}
+	}
+	return bucketLabel(0)
+}
+
+// bucketLabel of the idx bucket
+func bucketLabel(idx int) string {
+	if idx == len(PriceBuckets)-1 {
+		return fmt.Sprintf("%d..", PriceBuckets[idx])
+	}
+	return fmt.Sprintf("%d..%d", PriceBuckets[idx], PriceBuckets[idx+1]-1)
+}
+
+// RunQuery filter of crawl runs, newest first
+type RunQuery struct {
+	Task   string // empty for every task
+	Status string // empty for every status
+	Limit  int
+}
+
+// RunRepository bookkeeping of crawl runs
+type RunRepository interface {
+	// StartRun record a running run of task
+	StartRun(task string) (*schema.CrawlRun, error)
+	// FinishRun save counters and status of run, set finished time
+	FinishRun(run *schema.CrawlRun) error
+	// GetRun run by id
+	GetRun(id int) (*schema.CrawlRun, error)
+	// ListRuns runs newest first
+	ListRuns(q RunQuery) ([]schema.CrawlRun, error)
+	// LastSuccess the last successful run of every task
+	LastSuccess() ([]schema.CrawlRun, error)
+}
+
+// ProductRepository group items of different sources into products
+type ProductRepository interface {
+	// Unmatched items not in any product yet
+	Unmatched(limit int) ([]schema.Item, error)
+	// Similar matched items of other sources in the same unit, most similar name first
+	Similar(item schema.Item, name string, minScore float64, limit int) ([]schema.Item, error)
+	// CreateProduct insert product, fill p.Id
+	CreateProduct(p *schema.Product) error
+	// SetProduct put item into product
+	SetProduct(itemID, productID int) error
+	// GetProduct product by id
+	GetProduct(id int) (*schema.Product, error)
+	// Offers items of product, cheapest first
+	Offers(productID int) ([]schema.Item, error)
+}
+
+// CategoryRepository category tree of each source
+type CategoryRepository interface {
+	// SaveCategory insert or update c by (source, code), fill c.Id
+	SaveCategory(c *schema.Category) error
+	// GetCategory category by id, with item count
+	GetCategory(id int) (*schema.Category, error)
+	// Categories of source ordered by path, with item count, empty source for all
+	Categories(source string) ([]schema.Category, error)
+}
+
+// WatchRepository watches of price drop
+type WatchRepository interface {
+	// CreateWatch insert watch, fill w.Id
+	CreateWatch(w *schema.Watch) error
+	// GetWatch watch by id
+	GetWatch(id int) (*schema.Watch, error)
+	// DeleteWatch remove watch
+	DeleteWatch(id int) error
+	// MatchWatches watches item satisfies now
+	MatchWatches(item schema.Item) ([]schema.Watch, error)
+	// Notified remember when watch was notified
+	Notified(id int, at time.Time) error
+}
+
+// DeadLetterRepository undelivered webhook events
+type DeadLetterRepository interface {
+	// SaveDeadLetter insert d, fill d.Id
+	SaveDeadLetter(d *schema.DeadLetter) error
+}
@@ -0,0 +1,115 @@
+// Package unit extract quantity from item name or specification,
+// "500g", "1.5L", "330ml*6", "12入", so prices compare across pack sizes.
+package unit
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// base unit
+const (
+	Gram  = "g"
+	Milli = "ml"
+	Piece = "pc"
+)
+
+// Quantity amount in base unit
+type Quantity struct {
+	Amount float64
+	Unit   string
+}
+
+var (
+	// longer unit first, "ml" before "l", "公克" before "克"
+	measureRe = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(kg|公斤|mg|毫克|ml|毫升|c\.c\.|cc|公升|公克|g|克|l|升)`)
+	multiRe   = regexp.MustCompile(`^\s*[x×*]\s*(\d+)`)
+	countRe   = regexp.MustCompile(`(\d+)\s*(入|個|顆|包|片|支|罐|瓶|粒|枚|捲|卷|盒|袋|條|組)`)
+	timesRe   = regexp.MustCompile(`[x×*]\s*\d+`)
+
+	// unit to base unit and factor
+	measures = map[string]struct {
+		unit   string
+		factor float64
+	}{
+		"kg":   {Gram, 1000},
+		"公斤":   {Gram, 1000},
+		"mg":   {Gram, 0.001},
+		"毫克":   {Gram, 0.001},
+		"g":    {Gram, 1},
+		"公克":   {Gram, 1},
+		"克":    {Gram, 1},
+		"ml":   {Milli, 1},
+		"毫升":   {Milli, 1},
+		"cc":   {Milli, 1},
+		"c.c.": {Milli, 1},
+		"l":    {Milli, 1000},
+		"公升":   {Milli, 1000},
+		"升":    {Milli, 1000},
+	}
+)
+
+// Parse the first quantity found in s, multiply by pack count like "*6" or "6入"
+func Parse(s string) (Quantity, bool) {
+	s = strings.ToLower(s)
+
+	count := 1.0
+	if m := countRe.FindStringSubmatch(s); m != nil {
+		if n, err := strconv.ParseFloat(m[1], 64); err == nil && n > 0 {
+			count = n
+		}
+	}
+
+	for _, loc := range measureRe.FindAllStringSubmatchIndex(s, -1) {
+		// unit should not be followed by a letter, "5gal" is not gram, "500gx2" is
+		if end := loc[1]; end < len(s) && s[end] >= 'a' && s[end] <= 'z' && !multiRe.MatchString(s[end:]) {
+			continue
+		}
+		amount, err := strconv.ParseFloat(s[loc[2]:loc[3]], 64)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		m := measures[s[loc[4]:loc[5]]]
+
+		if multi := multiRe.FindStringSubmatch(s[loc[1]:]); multi != nil {
+			if n, err := strconv.ParseFloat(multi[1], 64); err == nil && n > 0 {
+				count = n
+			}
+		}
+		return Quantity{Amount: amount * m.factor * count, Unit: m.unit}, true
+	}
+
+	if count > 1 || countRe.MatchString(s) {
+		return Quantity{Amount: count, Unit: Piece}, true
+	}
+	return Quantity{}, false
+}
+
+// Strip quantity and pack count out of s, what left is the name
+func Strip(s string) string {
+	s = strings.ToLower(s)
+	s = measureRe.ReplaceAllString(s, " ")
+	s = countRe.ReplaceAllString(s, " ")
+	s = timesRe.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Per unit price is compared by, per 100g, per litre or per piece
+func Per(unit string) float64 {
+	switch unit {
+	case Gram:
+		return 100
+	case Milli:
+		return 1000
+	}
+	return 1
+}
+
+// Price of one Per unit, zero when quantity unknown
+func Price(price int, q Quantity) float64 {
+	if q.Amount <= 0 {
+		return 0
+	}
+	return float64(price) / q.Amount * Per(q.Unit)
+}
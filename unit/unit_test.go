@@ -0,0 +1,48 @@
+package unit
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+		ok   bool
+	}{
+		{"500g", Quantity{500, Gram}, true},
+		{"1公斤", Quantity{1000, Gram}, true},
+		{"1.5L", Quantity{1500, Milli}, true},
+		{"鮮乳 1857ml", Quantity{1857, Milli}, true},
+		{"330ml*6", Quantity{1980, Milli}, true},
+		{"500gx2", Quantity{1000, Gram}, true},
+		{"6入 250ml", Quantity{1500, Milli}, true},
+		{"12入", Quantity{12, Piece}, true},
+		{"2包", Quantity{2, Piece}, true},
+		// gallon is not gram
+		{"5gal", Quantity{}, false},
+		{"蘋果", Quantity{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		price int
+		q     Quantity
+		want  float64
+	}{
+		{50, Quantity{500, Gram}, 10},
+		{90, Quantity{1500, Milli}, 60},
+		{120, Quantity{12, Piece}, 10},
+		{99, Quantity{}, 0},
+	}
+	for _, tt := range tests {
+		if got := Price(tt.price, tt.q); got != tt.want {
+			t.Errorf("Price(%d, %v) = %v, want %v", tt.price, tt.q, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package webhook POST signed item events to downstream services,
+// retry with backoff and keep what still failed as dead letter.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"honestman/metrics"
+	"honestman/schema"
+	"honestman/store"
+)
+
+// event types
+const (
+	ItemCreated     = "item.created"
+	PriceChanged    = "item.price_changed"
+	ItemDisappeared = "item.disappeared"
+)
+
+// request headers
+const (
+	SignatureHeader = "X-Honestman-Signature"
+	EventHeader     = "X-Honestman-Event"
+	DeliveryHeader  = "X-Honestman-Delivery"
+)
+
+const (
+	signaturePrefix  = "sha256="
+	defaultWorkers   = 4
+	defaultQueueSize = 1000
+)
+
+// Event body of webhook
+type Event struct {
+	Id      string      `json:"id"`
+	Type    string      `json:"type"`
+	Created time.Time   `json:"created"`
+	Item    schema.Item `json:"item"`
+}
+
+// Endpoint one downstream receiver
+type Endpoint struct {
+	URL    string   `json:"url" yaml:"url"`
+	Secret string   `json:"secret" yaml:"secret"` // HMAC-SHA256 key of body
+	Events []string `json:"events" yaml:"events"` // empty for every event
+}
+
+// wants event type
+func (e Endpoint) wants(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Config of dispatcher
+type Config struct {
+	Endpoints []Endpoint `json:"endpoints" yaml:"endpoints"`
+	Retries   int        `json:"retries" yaml:"retries"` // attempts after the first one
+	Backoff   int64      `json:"backoff" yaml:"backoff"` // seconds before the first retry, doubled every retry
+	Timeout   int64      `json:"timeout" yaml:"timeout"` // seconds of each request
+	Queue     int        `json:"queue" yaml:"queue"`
+}
+
+// delivery one event to one endpoint
+type delivery struct {
+	endpoint Endpoint
+	event    Event
+	body     []byte
+}
+
+// errQueueFull last error of deliveries which never got a worker
+var errQueueFull = errors.New("queue full")
+
+// Dispatcher queue events and deliver them by a few workers
+type Dispatcher struct {
+	conf        Config
+	client      *http.Client
+	backoff     time.Duration
+	deadLetters store.DeadLetterRepository
+	queue       chan delivery
+	overflow    chan delivery // saved as dead letter by Run, not by the publisher
+}
+
+// New dispatcher, zero config values use defaults
+func New(conf Config, deadLetters store.DeadLetterRepository) *Dispatcher {
+	if conf.Retries < 0 {
+		conf.Retries = 0
+	}
+	if conf.Backoff <= 0 {
+		conf.Backoff = 2
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 10
+	}
+	if conf.Queue <= 0 {
+		conf.Queue = defaultQueueSize
+	}
+	return &Dispatcher{
+		conf:        conf,
+		client:      &http.Client{Timeout: time.Duration(conf.Timeout) * time.Second},
+		backoff:     time.Duration(conf.Backoff) * time.Second,
+		deadLetters: deadLetters,
+		queue:       make(chan delivery, conf.Queue),
+		overflow:    make(chan delivery, conf.Queue),
+	}
+}
+
+// Sign body with secret, the value of X-Honestman-Signature
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify signature of body, for receivers written in Go
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Publish event of item to every endpoint wants it, never block the crawler,
+// a full queue goes to the dead letters and a full overflow is dropped
+func (d *Dispatcher) Publish(eventType string, item schema.Item) {
+	event := Event{
+		Id:      newID(),
+		Type:    eventType,
+		Created: time.Now().Truncate(time.Second),
+		Item:    item,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Println("webhook:", err)
+		return
+	}
+
+	for _, e := range d.conf.Endpoints {
+		if !e.wants(eventType) {
+			continue
+		}
+		dl := delivery{endpoint: e, event: event, body: body}
+		select {
+		case d.queue <- dl:
+			continue
+		default:
+		}
+		select {
+		case d.overflow <- dl:
+		default:
+			log.Println("webhook: overflow full, drop", eventType, e.URL)
+			metrics.WebhookDropped.WithLabelValues(eventType).Inc()
+		}
+	}
+}
+
+// Run deliver until ctx done, pending ones become dead letters
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case dl := <-d.overflow:
+				d.dead(dl, 0, errQueueFull)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < defaultWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case dl := <-d.queue:
+					d.deliver(ctx, dl)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		select {
+		case dl := <-d.queue:
+			d.dead(dl, 0, ctx.Err())
+		case dl := <-d.overflow:
+			d.dead(dl, 0, errQueueFull)
+		default:
+			return
+		}
+	}
+}
+
+// deliver with retry, backoff 2s, 4s, 8s ...
+func (d *Dispatcher) deliver(ctx context.Context, dl delivery) {
+	backoff := d.backoff
+	attempts := 0
+	for {
+		attempts++
+		err := d.post(ctx, dl)
+		if err == nil {
+			return
+		}
+		if attempts > d.conf.Retries {
+			d.dead(dl, attempts, err)
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.dead(dl, attempts, err)
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, dl delivery) error {
+	req, err := http.NewRequest("POST", dl.endpoint.URL, bytes.NewReader(dl.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.event.Type)
+	req.Header.Set(DeliveryHeader, dl.event.Id)
+	if dl.endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(dl.endpoint.Secret, dl.body))
+	}
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", dl.endpoint.URL, resp.Status)
+	}
+	return nil
+}
+
+// dead keep undelivered event for replay
+func (d *Dispatcher) dead(dl delivery, attempts int, err error) {
+	log.Println("webhook: dead letter", dl.event.Type, dl.endpoint.URL, err)
+	letter := &schema.DeadLetter{
+		Url:       dl.endpoint.URL,
+		Event:     dl.event.Type,
+		Payload:   string(dl.body),
+		Attempts:  attempts,
+		LastError: err.Error(),
+	}
+	if err := d.deadLetters.SaveDeadLetter(letter); err != nil {
+		log.Println("webhook:", err)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
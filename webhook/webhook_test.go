@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"honestman/schema"
+	"honestman/store"
+)
+
+// letters tell the test every saved dead letter
+type letters struct {
+	*store.Memory
+	saved chan schema.DeadLetter
+}
+
+func newLetters() *letters {
+	return &letters{Memory: store.NewMemory(), saved: make(chan schema.DeadLetter, 10)}
+}
+
+func (l *letters) SaveDeadLetter(d *schema.DeadLetter) error {
+	err := l.Memory.SaveDeadLetter(d)
+	l.saved <- *d
+	return err
+}
+
+// receiver answer the status codes in turn, the last one ever after
+type receiver struct {
+	mu     sync.Mutex
+	codes  []int
+	events []string
+}
+
+func newReceiver(codes ...int) (*httptest.Server, *receiver) {
+	r := &receiver{codes: codes}
+	return httptest.NewServer(r), r
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	code := r.codes[0]
+	if len(r.codes) > 1 {
+		r.codes = r.codes[1:]
+	}
+	r.events = append(r.events, req.Header.Get(EventHeader))
+	w.WriteHeader(code)
+}
+
+// newDispatcher with a short backoff
+func newDispatcher(conf Config, deadLetters store.DeadLetterRepository) *Dispatcher {
+	d := New(conf, deadLetters)
+	d.backoff = time.Millisecond
+	return d
+}
+
+// drain deliver every queued event in turn
+func drain(d *Dispatcher) {
+	for len(d.queue) > 0 {
+		d.deliver(context.Background(), <-d.queue)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"item.price_changed"}`)
+	sig := Sign("secret", body)
+	if !strings.HasPrefix(sig, signaturePrefix) {
+		t.Fatalf("Sign = %q, want prefix %q", sig, signaturePrefix)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"same", "secret", body, sig, true},
+		{"other secret", "other", body, sig, false},
+		{"body changed", "secret", []byte(`{"id":"2","type":"item.price_changed"}`), sig, false},
+		{"without prefix", "secret", body, strings.TrimPrefix(sig, signaturePrefix), false},
+		{"empty", "secret", body, "", false},
+	}
+	for _, tt := range tests {
+		if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	server, r := newReceiver(500, 502, 200)
+	defer server.Close()
+	dead := newLetters()
+
+	d := newDispatcher(Config{Endpoints: []Endpoint{{URL: server.URL}}, Retries: 3}, dead)
+	d.Publish(PriceChanged, schema.Item{Id: 1})
+	drain(d)
+
+	if n := len(r.events); n != 3 {
+		t.Errorf("%d requests, want delivered on the third", n)
+	}
+	if n := len(dead.DeadLetters()); n != 0 {
+		t.Errorf("%d dead letters, want none", n)
+	}
+}
+
+func TestGiveUp(t *testing.T) {
+	server, r := newReceiver(503)
+	defer server.Close()
+	dead := newLetters()
+
+	d := newDispatcher(Config{Endpoints: []Endpoint{{URL: server.URL}}, Retries: 2}, dead)
+	d.Publish(ItemDisappeared, schema.Item{Id: 1})
+	drain(d)
+
+	if n := len(r.events); n != 3 {
+		t.Errorf("%d requests, want the first and 2 retries", n)
+	}
+	letters := dead.DeadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("%d dead letters, want 1", len(letters))
+	}
+	if letter := letters[0]; letter.Attempts != 3 || letter.Event != ItemDisappeared || letter.Url != server.URL {
+		t.Errorf("dead letter %+v, want 3 attempts of %s to %s", letter, ItemDisappeared, server.URL)
+	}
+	if !strings.Contains(letters[0].LastError, "503") {
+		t.Errorf("last error %q, want the 503", letters[0].LastError)
+	}
+}
+
+func TestFilterEvents(t *testing.T) {
+	all, everyEvent := newReceiver(200)
+	defer all.Close()
+	changed, priceOnly := newReceiver(200)
+	defer changed.Close()
+
+	d := newDispatcher(Config{Endpoints: []Endpoint{
+		{URL: all.URL},
+		{URL: changed.URL, Events: []string{PriceChanged}},
+	}}, newLetters())
+	for _, event := range []string{ItemCreated, PriceChanged, ItemDisappeared} {
+		d.Publish(event, schema.Item{Id: 1})
+	}
+	drain(d)
+
+	if got, want := strings.Join(everyEvent.events, ","), "item.created,item.price_changed,item.disappeared"; got != want {
+		t.Errorf("endpoint without events got %s, want %s", got, want)
+	}
+	if got := strings.Join(priceOnly.events, ","); got != PriceChanged {
+		t.Errorf("filtered endpoint got %s, want %s only", got, PriceChanged)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	dead := newLetters()
+
+	// one queued, one overflow and one dropped, nothing saved by the publisher
+	d := newDispatcher(Config{Endpoints: []Endpoint{{URL: "http://127.0.0.1:1"}}, Queue: 1}, dead)
+	for id := 1; id <= 3; id++ {
+		d.Publish(PriceChanged, schema.Item{Id: id})
+	}
+	if len(d.queue) != 1 || len(d.overflow) != 1 {
+		t.Fatalf("queue %d overflow %d, want 1 each", len(d.queue), len(d.overflow))
+	}
+	if n := len(dead.saved); n != 0 {
+		t.Fatalf("publish saved %d dead letters, want none", n)
+	}
+
+	// the overflow saved by Run
+	<-d.queue
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+	select {
+	case letter := <-dead.saved:
+		if letter.LastError != errQueueFull.Error() || letter.Attempts != 0 {
+			t.Errorf("dead letter %+v, want the overflow never attempted", letter)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no dead letter of the overflow")
+	}
+}